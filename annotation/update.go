@@ -0,0 +1,152 @@
+package annotation
+
+import (
+	"regexp"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoDatasource    = errors.New("annotation has no datasource")
+	ErrorWrongDepName    = errors.New("depName should be owner/repo")
+	ErrorNoVersion       = errors.New("annotated line has no version")
+	ErrorWrongVersioning = errors.New("versioning is not supported")
+)
+
+var (
+	annotationRe = regexp.MustCompile(`(#|//|--|;)\s*zoi:\s*(.*)$`)
+	optionRe     = regexp.MustCompile(`([A-Za-z]+)=(\S+)`)
+	depNameRe    = regexp.MustCompile(`^(github\.com/)?([^/\s]+)/([^/\s]+)$`)
+	versionRe    = regexp.MustCompile(`\bv?[0-9]+(\.[0-9]+)+([-+][0-9A-Za-z.-]+)?\b`)
+)
+
+// Resolvers The resolvers of the datasources, like `github-tags` and
+// `github-releases`.
+type Resolvers map[string]gh.Resolver
+
+// NewResolvers Return the GitHub tags and releases datasources.
+func NewResolvers(token string) Resolvers {
+	return Resolvers{
+		"github-tags":     gh.NewResolver(true, token),
+		"github-releases": gh.NewResolver(false, token),
+	}
+}
+
+// Annotation A `zoi:` comment that binds the version of the next line to a
+// datasource, like
+// `# zoi: datasource=github-tags depName=kubernetes/kubernetes`.
+type Annotation struct {
+	Datasource string
+	Owner      string
+	Repo       string
+	Versioning string
+}
+
+// Parse Parse the annotation of a line, returning nil if the line has
+// none.
+func Parse(line string) (*Annotation, error) {
+	found := annotationRe.FindStringSubmatch(line)
+	if found == nil {
+		return nil, nil
+	}
+
+	ret := Annotation{Versioning: "semver"}
+
+	for _, option := range optionRe.FindAllStringSubmatch(found[2], -1) {
+		switch option[1] {
+		case "datasource":
+			ret.Datasource = option[2]
+		case "depName":
+			dep := depNameRe.FindStringSubmatch(option[2])
+			if dep == nil {
+				return nil, ErrorWrongDepName
+			}
+
+			ret.Owner, ret.Repo = dep[2], dep[3]
+		case "versioning":
+			ret.Versioning = option[2]
+		default:
+			log.WithFields(log.Fields{
+				"option": option[0],
+			}).Debug("Unknown annotation option")
+		}
+	}
+
+	if ret.Datasource == "" {
+		return nil, ErrorNoDatasource
+	}
+
+	if ret.Owner == "" {
+		return nil, ErrorWrongDepName
+	}
+
+	if ret.Versioning != "semver" {
+		return nil, ErrorWrongVersioning
+	}
+
+	return &ret, nil
+}
+
+// update Replace the first version of the line with the next one of the
+// datasource, keeping its `v` prefix and its number of components.
+func (a *Annotation) update(line string, resolve gh.Resolver) (string, error) {
+	found := versionRe.FindStringIndex(line)
+	if found == nil {
+		return line, ErrorNoVersion
+	}
+
+	current := line[found[0]:found[1]]
+
+	constraint, err := versions.ParseConstraint(current)
+	if err != nil {
+		return line, err
+	}
+
+	next := resolve.Next(a.Owner, a.Repo, current)
+	if !versions.Less(current, next) {
+		return line, nil
+	}
+
+	return line[0:found[0]] + constraint.Bump(next) + line[found[1]:], nil
+}
+
+// Update Update the versions of the lines that follow a `zoi:` annotation.
+func Update(lines []string, resolvers Resolvers) []string {
+	ret := append([]string{}, lines...)
+
+	for i := 0; i+1 < len(lines); i++ {
+		annotation, err := Parse(lines[i])
+		if annotation == nil {
+			if err != nil {
+				log.WithFields(log.Fields{
+					"err":  err,
+					"line": lines[i],
+				}).Warning("Cannot parse annotation")
+			}
+
+			continue
+		}
+
+		resolve, ok := resolvers[annotation.Datasource]
+		if !ok {
+			log.WithFields(log.Fields{
+				"datasource": annotation.Datasource,
+			}).Warning("Unknown datasource")
+
+			continue
+		}
+
+		ret[i+1], err = annotation.update(ret[i+1], resolve)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":  err,
+				"line": ret[i+1],
+			}).Warning("Cannot update annotated line")
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,96 @@
+package annotation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  *Annotation
+		err  error
+	}{
+		{
+			name: "shell comment",
+			in:   "# zoi: datasource=github-tags depName=kubernetes/kubernetes versioning=semver",
+			out:  &Annotation{Datasource: "github-tags", Owner: "kubernetes", Repo: "kubernetes", Versioning: "semver"},
+		},
+		{
+			name: "trailing comment with a github.com depName",
+			in:   "  // zoi: datasource=github-releases depName=github.com/owner/repo",
+			out:  &Annotation{Datasource: "github-releases", Owner: "owner", Repo: "repo", Versioning: "semver"},
+		},
+		{
+			name: "not an annotation",
+			in:   "# install kubectl",
+		},
+		{
+			name: "missing datasource",
+			in:   "# zoi: depName=owner/repo",
+			err:  ErrorNoDatasource,
+		},
+		{
+			name: "wrong depName",
+			in:   "# zoi: datasource=github-tags depName=repo",
+			err:  ErrorWrongDepName,
+		},
+		{
+			name: "unsupported versioning",
+			in:   "# zoi: datasource=github-tags depName=owner/repo versioning=pep440",
+			err:  ErrorWrongVersioning,
+		},
+	}
+
+	for _, test := range cases {
+		annotation, err := Parse(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, annotation, test.name)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	resolvers := Resolvers{
+		"github-tags": gh.StaticResolver(map[string]string{
+			"kubernetes/kubernetes": "v1.29.1",
+			"helm/helm":             "v3.14.0",
+		}),
+		"github-releases": gh.StaticResolver(map[string]string{
+			"owner/repo": "2.0.0",
+		}),
+	}
+
+	in := heredoc.Doc(`
+		# zoi: datasource=github-tags depName=kubernetes/kubernetes versioning=semver
+		KUBECTL_VERSION=1.28.2
+		# zoi: datasource=github-tags depName=helm/helm
+		  helm_version: "v3.12"
+		# zoi: datasource=github-releases depName=owner/repo
+		version = "2.1.0"
+		# zoi: datasource=docker depName=library/alpine
+		ALPINE=3.18.0
+		# zoi: datasource=github-tags depName=helm/helm
+		no version here
+		UNANNOTATED_VERSION=1.0.0
+	`)
+
+	out := Update(strings.Split(in, "\n"), resolvers)
+	assert.Equal(t, heredoc.Doc(`
+		# zoi: datasource=github-tags depName=kubernetes/kubernetes versioning=semver
+		KUBECTL_VERSION=1.29.1
+		# zoi: datasource=github-tags depName=helm/helm
+		  helm_version: "v3.14"
+		# zoi: datasource=github-releases depName=owner/repo
+		version = "2.1.0"
+		# zoi: datasource=docker depName=library/alpine
+		ALPINE=3.18.0
+		# zoi: datasource=github-tags depName=helm/helm
+		no version here
+		UNANNOTATED_VERSION=1.0.0
+	`), strings.Join(out, "\n"))
+}
@@ -0,0 +1,162 @@
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultGalaxy = "https://galaxy.ansible.com"
+
+var (
+	ErrorNotFound  = errors.New("role or collection not found in galaxy")
+	ErrorWrongName = errors.New("galaxy names should be namespace.name")
+)
+
+// Galaxy A client for the Ansible Galaxy API.
+type Galaxy struct {
+	// URL overrides Ansible Galaxy, for example to use a local server.
+	URL    string
+	Client *http.Client
+}
+
+func (g *Galaxy) client() *http.Client {
+	if g.Client == nil {
+		return http.DefaultClient
+	}
+
+	return g.Client
+}
+
+func (g *Galaxy) base() string {
+	if g.URL == "" {
+		return DefaultGalaxy
+	}
+
+	return strings.TrimSuffix(g.URL, "/")
+}
+
+func (g *Galaxy) get(address string, v interface{}) error {
+	resp, err := g.client().Get(address)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrorNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func split(name string) (string, string, error) {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrorWrongName
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// CollectionVersions List the versions of a collection like
+// `community.general`, following the pages of the API.
+func (g *Galaxy) CollectionVersions(name string) ([]string, error) {
+	namespace, collection, err := split(name)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(g.base() + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	address := fmt.Sprintf("%s/api/v3/collections/%s/%s/versions/?limit=100", g.base(), namespace, collection)
+
+	var ret []string
+
+	for address != "" {
+		var page struct {
+			Data []struct {
+				Version string `json:"version"`
+			} `json:"data"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}
+
+		err := g.get(address, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range page.Data {
+			ret = append(ret, version.Version)
+		}
+
+		address = ""
+
+		if page.Links.Next != "" {
+			next, err := url.Parse(page.Links.Next)
+			if err != nil {
+				return nil, err
+			}
+
+			address = base.ResolveReference(next).String()
+		}
+	}
+
+	return ret, nil
+}
+
+// RoleVersions List the versions of a role like `geerlingguy.docker`.
+func (g *Galaxy) RoleVersions(name string) ([]string, error) {
+	namespace, role, err := split(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			SummaryFields struct {
+				Versions []struct {
+					Name string `json:"name"`
+				} `json:"versions"`
+			} `json:"summary_fields"`
+		} `json:"results"`
+	}
+
+	err = g.get(fmt.Sprintf(
+		"%s/api/v1/roles/?owner__username=%s&name=%s",
+		g.base(), url.QueryEscape(namespace), url.QueryEscape(role),
+	), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Results) == 0 {
+		return nil, ErrorNotFound
+	}
+
+	var ret []string
+	for _, version := range resp.Results[0].SummaryFields.Versions {
+		ret = append(ret, version.Name)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,147 @@
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCollections The collection versions of the local Galaxy.
+var testCollections = map[string][]string{
+	"community/general": {"6.0.0", "7.5.0", "8.4.0", "9.0.0-beta1"},
+	"ansible/posix":     {"1.4.0", "1.5.4"},
+}
+
+// testRoles The role versions of the local Galaxy.
+var testRoles = map[string][]string{
+	"geerlingguy/docker": {"6.0.0", "7.1.0", "7.2.0"},
+	"geerlingguy/pip":    {"2.2.0", "3.0.3"},
+}
+
+// testGalaxy A local stand-in of Ansible Galaxy that returns one collection
+// version per page, to test the pagination.
+func testGalaxy(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+		switch {
+		case len(parts) == 6 && parts[1] == "v3" && parts[5] == "versions":
+			versions, ok := testCollections[parts[3]+"/"+parts[4]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+			var next interface{}
+			if offset+1 < len(versions) {
+				next = fmt.Sprintf("%s?limit=1&offset=%d", r.URL.Path, offset+1)
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":  []map[string]string{{"version": versions[offset]}},
+				"links": map[string]interface{}{"next": next},
+			})
+		case r.URL.Path == "/api/v1/roles/":
+			query := r.URL.Query()
+			results := []interface{}{}
+
+			if versions, ok := testRoles[query.Get("owner__username")+"/"+query.Get("name")]; ok {
+				var names []map[string]string
+				for _, version := range versions {
+					names = append(names, map[string]string{"name": version})
+				}
+
+				results = append(results, map[string]interface{}{
+					"summary_fields": map[string]interface{}{"versions": names},
+				})
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"geerlingguy/ansible-role-java": "2.5.0",
+	"owner/collection":              "v1.3.0",
+})
+
+func TestCollectionVersions(t *testing.T) {
+	server := testGalaxy(t)
+	defer server.Close()
+
+	galaxy := Galaxy{URL: server.URL}
+
+	var cases = []struct {
+		name string
+		in   string
+		out  []string
+		err  error
+	}{
+		{
+			name: "all the pages are read",
+			in:   "community.general",
+			out:  []string{"6.0.0", "7.5.0", "8.4.0", "9.0.0-beta1"},
+		},
+		{
+			name: "missing collection",
+			in:   "missing.collection",
+			err:  ErrorNotFound,
+		},
+		{
+			name: "name without namespace",
+			in:   "general",
+			err:  ErrorWrongName,
+		},
+	}
+
+	for _, test := range cases {
+		versions, err := galaxy.CollectionVersions(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, versions, test.name)
+	}
+}
+
+func TestRoleVersions(t *testing.T) {
+	server := testGalaxy(t)
+	defer server.Close()
+
+	galaxy := Galaxy{URL: server.URL}
+
+	var cases = []struct {
+		name string
+		in   string
+		out  []string
+		err  error
+	}{
+		{
+			name: "role",
+			in:   "geerlingguy.docker",
+			out:  []string{"6.0.0", "7.1.0", "7.2.0"},
+		},
+		{
+			name: "missing role",
+			in:   "geerlingguy.missing",
+			err:  ErrorNotFound,
+		},
+	}
+
+	for _, test := range cases {
+		versions, err := galaxy.RoleVersions(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, versions, test.name)
+	}
+}
@@ -0,0 +1,168 @@
+package ansible
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/mhristof/zoi/yamlnode"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrorNoRequirements = errors.New("no `roles:` or `collections:` found")
+)
+
+var githubRe = regexp.MustCompile(`github\.com[/:]([^/]+)/([^/#,]+?)(\.git)?([#,/].*)?$`)
+
+// IsRequirements Check if the file is an Ansible Galaxy requirements file,
+// like `requirements.yml` or `roles/requirements.yml`.
+func IsRequirements(path string) bool {
+	base := filepath.Base(path)
+
+	return base == "requirements.yml" || base == "requirements.yaml"
+}
+
+// requirement A role or a collection of a requirements file.
+type requirement struct {
+	// source is the `src:` of the entry, or its `name:`.
+	source     string
+	version    *yaml.Node
+	collection bool
+}
+
+// requirements Find the roles and collections that have a version.
+func requirements(root *yaml.Node) ([]requirement, error) {
+	var ret []requirement
+
+	switch root.Kind {
+	case yaml.SequenceNode:
+		// the older format with a list of roles only
+		ret = append(ret, entries(root, false)...)
+	case yaml.MappingNode:
+		roles := yamlnode.MappingValue(root, "roles")
+		collections := yamlnode.MappingValue(root, "collections")
+
+		if roles == nil && collections == nil {
+			return nil, ErrorNoRequirements
+		}
+
+		if roles != nil {
+			ret = append(ret, entries(roles, false)...)
+		}
+
+		if collections != nil {
+			ret = append(ret, entries(collections, true)...)
+		}
+	default:
+		return nil, ErrorNoRequirements
+	}
+
+	return ret, nil
+}
+
+func entries(list *yaml.Node, collection bool) []requirement {
+	var ret []requirement
+
+	if list.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, entry := range list.Content {
+		version := yamlnode.MappingValue(entry, "version")
+		if version == nil || version.Kind != yaml.ScalarNode || version.Value == "" {
+			continue
+		}
+
+		source := yamlnode.MappingValue(entry, "src")
+		if source == nil {
+			source = yamlnode.MappingValue(entry, "name")
+		}
+
+		if source == nil || source.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		ret = append(ret, requirement{
+			source:     source.Value,
+			version:    version,
+			collection: collection,
+		})
+	}
+
+	return ret
+}
+
+// next Find the next version of a requirement, keeping its constraint
+// operator for collections like `>=6.0.0`.
+func (r requirement) next(galaxy *Galaxy, resolve gh.Resolver) string {
+	current := r.version.Value
+
+	if found := githubRe.FindStringSubmatch(r.source); len(found) > 0 {
+		return resolve.Next(found[1], found[2], current)
+	}
+
+	constraint, err := versions.ParseConstraint(current)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":     err,
+			"source":  r.source,
+			"version": current,
+		}).Debug("Cannot parse version, leaving it as is")
+
+		return current
+	}
+
+	available, err := galaxy.RoleVersions(r.source)
+	if r.collection {
+		available, err = galaxy.CollectionVersions(r.source)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":    err,
+			"source": r.source,
+		}).Error("Cannot list galaxy versions")
+
+		return current
+	}
+
+	latest, err := versions.Latest(available)
+	if err != nil || !versions.Less(constraint.Version, latest) {
+		return current
+	}
+
+	return constraint.Bump(latest)
+}
+
+// Update Update the `version:` of the roles and collections of an Ansible
+// Galaxy requirements file. Galaxy names are resolved with the Galaxy API
+// and GitHub `src:` entries with the resolver.
+func Update(bytesIn []byte, galaxy *Galaxy, resolve gh.Resolver) (string, error) {
+	var document yaml.Node
+
+	err := yaml.Unmarshal(bytesIn, &document)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse yaml")
+	}
+
+	if len(document.Content) == 0 {
+		return "", ErrorNoRequirements
+	}
+
+	found, err := requirements(document.Content[0])
+	if err != nil {
+		return "", err
+	}
+
+	updates := map[*yaml.Node]string{}
+
+	for _, requirement := range found {
+		updates[requirement.version] = requirement.next(galaxy, resolve)
+	}
+
+	return yamlnode.Replace(bytesIn, updates), nil
+}
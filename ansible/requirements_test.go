@@ -0,0 +1,104 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	server := testGalaxy(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "roles and collections",
+			in: heredoc.Doc(`
+				---
+				roles:
+				  # docker
+				  - name: geerlingguy.docker
+				    version: 6.0.0
+				  - src: geerlingguy.pip
+				    version: "2.2.0"
+				  - src: https://github.com/geerlingguy/ansible-role-java.git
+				    name: java
+				    version: 2.1.0
+				  - name: geerlingguy.missing
+				    version: 1.0.0
+				  - name: geerlingguy.unpinned
+				collections:
+				  - name: community.general
+				    version: '>=6.0.0'
+				  - name: ansible.posix
+				    version: 1.4.0
+				  - name: git+https://github.com/owner/collection.git
+				    type: git
+				    version: v1.0.0
+				  - name: ansible.utils
+				    version: '>=2.0.0,<3.0.0'
+			`),
+			out: heredoc.Doc(`
+				---
+				roles:
+				  # docker
+				  - name: geerlingguy.docker
+				    version: 7.2.0
+				  - src: geerlingguy.pip
+				    version: "3.0.3"
+				  - src: https://github.com/geerlingguy/ansible-role-java.git
+				    name: java
+				    version: 2.5.0
+				  - name: geerlingguy.missing
+				    version: 1.0.0
+				  - name: geerlingguy.unpinned
+				collections:
+				  - name: community.general
+				    version: '>=8.4.0'
+				  - name: ansible.posix
+				    version: 1.5.4
+				  - name: git+https://github.com/owner/collection.git
+				    type: git
+				    version: v1.3.0
+				  - name: ansible.utils
+				    version: '>=2.0.0,<3.0.0'
+			`),
+		},
+		{
+			name: "list of roles",
+			in: heredoc.Doc(`
+				- src: geerlingguy.docker
+				  version: 7.1.0
+			`),
+			out: heredoc.Doc(`
+				- src: geerlingguy.docker
+				  version: 7.2.0
+			`),
+		},
+		{
+			name: "not a requirements file",
+			in: heredoc.Doc(`
+				foo: bar
+			`),
+			err: ErrorNoRequirements,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := Update([]byte(test.in), &Galaxy{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestIsRequirements(t *testing.T) {
+	assert.True(t, IsRequirements("roles/requirements.yml"))
+	assert.True(t, IsRequirements("collections/requirements.yaml"))
+	assert.False(t, IsRequirements("requirements.txt"))
+}
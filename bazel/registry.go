@@ -0,0 +1,82 @@
+package bazel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultRegistry = "https://bcr.bazel.build"
+
+var (
+	ErrorModuleNotFound = errors.New("module not found in the registry")
+)
+
+// Registry A client for a Bazel registry, like the Bazel Central Registry.
+type Registry struct {
+	// URL overrides the Bazel Central Registry, for example to use a local
+	// registry.
+	URL    string
+	Client *http.Client
+}
+
+func (r *Registry) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+
+	return r.Client
+}
+
+// Versions List the versions of a module that are not yanked.
+func (r *Registry) Versions(module string) ([]string, error) {
+	base := r.URL
+	if base == "" {
+		base = DefaultRegistry
+	}
+
+	address := fmt.Sprintf("%s/modules/%s/metadata.json", strings.TrimSuffix(base, "/"), module)
+
+	resp, err := r.client().Get(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrorModuleNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	var metadata struct {
+		Versions       []string          `json:"versions"`
+		YankedVersions map[string]string `json:"yanked_versions"`
+	}
+
+	err = json.Unmarshal(body, &metadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse %s", address)
+	}
+
+	var ret []string
+
+	for _, version := range metadata.Versions {
+		if _, yanked := metadata.YankedVersions[version]; !yanked {
+			ret = append(ret, version)
+		}
+	}
+
+	return ret, nil
+}
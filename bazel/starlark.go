@@ -0,0 +1,145 @@
+package bazel
+
+import (
+	"strings"
+)
+
+// literal A string literal of a Starlark file and the position of its
+// contents, without the quotes.
+type literal struct {
+	start int
+	end   int
+	value string
+}
+
+// call A call of a Starlark rule, like `http_archive(...)`, with the string
+// literals of each of its keyword arguments.
+type call struct {
+	name string
+	args map[string][]literal
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdent(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// skipComment Return the position of the end of the line.
+func skipComment(src string, i int) int {
+	end := strings.IndexByte(src[i:], '\n')
+	if end < 0 {
+		return len(src)
+	}
+
+	return i + end
+}
+
+// scanString Scan a string literal starting at its quote and return the
+// position after the closing quote and the literal.
+func scanString(src string, i int) (int, literal) {
+	quote := src[i]
+	start := i + 1
+
+	for j := start; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1, literal{start: start, end: j, value: src[start:j]}
+		case '\n':
+			return j, literal{start: start, end: j, value: src[start:j]}
+		}
+	}
+
+	return len(src), literal{start: start, end: len(src), value: src[start:]}
+}
+
+// scanArgs Scan the arguments of a call, starting after its opening
+// parenthesis, and return the position after the closing one.
+func scanArgs(src string, i int) (int, map[string][]literal) {
+	args := map[string][]literal{}
+	depth := 1
+	key := ""
+
+	for i < len(src) {
+		c := src[i]
+
+		switch {
+		case c == '#':
+			i = skipComment(src, i)
+		case c == '"' || c == '\'':
+			var value literal
+
+			i, value = scanString(src, i)
+			if key != "" {
+				args[key] = append(args[key], value)
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdent(src[i]) {
+				i++
+			}
+
+			next := strings.TrimLeft(src[i:], " \t\r\n")
+			if depth == 1 && strings.HasPrefix(next, "=") && !strings.HasPrefix(next, "==") {
+				key = src[start:i]
+			}
+		case c == '(' || c == '[' || c == '{':
+			depth++
+			i++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+			i++
+
+			if depth == 0 {
+				return i, args
+			}
+		case c == ',' && depth == 1:
+			key = ""
+			i++
+		default:
+			i++
+		}
+	}
+
+	return i, args
+}
+
+// calls Find the calls of the rules in a Starlark file, skipping comments
+// and strings.
+func calls(src string, rules map[string]bool) []call {
+	var ret []call
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '#':
+			i = skipComment(src, i)
+		case c == '"' || c == '\'':
+			i, _ = scanString(src, i)
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdent(src[i]) {
+				i++
+			}
+
+			name := src[start:i]
+			next := strings.TrimLeft(src[i:], " \t")
+
+			if rules[name] && strings.HasPrefix(next, "(") && (start == 0 || src[start-1] != '.') {
+				var args map[string][]literal
+
+				i, args = scanArgs(src, len(src)-len(next)+1)
+				ret = append(ret, call{name: name, args: args})
+			}
+		default:
+			i++
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,170 @@
+package bazel
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoRules = errors.New("no http_archive or bazel_dep rules found")
+)
+
+// rules The rules that are updated.
+var rules = map[string]bool{
+	"http_archive": true,
+	"bazel_dep":    true,
+}
+
+// edit A replacement of the contents of a string literal.
+type edit struct {
+	literal
+	updated string
+}
+
+// IsBazel Check if a file name looks like a Bazel `WORKSPACE` or
+// `MODULE.bazel` file.
+func IsBazel(file string) bool {
+	switch filepath.Base(file) {
+	case "WORKSPACE", "WORKSPACE.bazel", "WORKSPACE.bzlmod", "MODULE.bazel":
+		return true
+	}
+
+	return false
+}
+
+// replaceVersion Replace the tag, and the version without the `v` prefix,
+// in a string, like the `r-1.2.3` of `v1.2.3`.
+func replaceVersion(value, tag, next string) string {
+	value = strings.ReplaceAll(value, tag, next)
+
+	return strings.ReplaceAll(value, strings.TrimPrefix(tag, "v"), strings.TrimPrefix(next, "v"))
+}
+
+// updateArchive Update the URLs of an `http_archive` to the next GitHub
+// release, fixing its `strip_prefix` and recomputing its `sha256` and
+// `integrity`. The rule is left as it is if the new archive cannot be
+// downloaded, as the old checksum would break the build.
+func updateArchive(args map[string][]literal, fetcher *checksum.Fetcher, resolve gh.Resolver) []edit {
+	urls := append(append([]literal{}, args["url"]...), args["urls"]...)
+
+	var owner, repo, tag, archive string
+
+	for _, url := range urls {
+		if release, err := gh.ParseReleaseUrl(url.value); err == nil {
+			owner, repo, tag, archive = release.Owner, release.Repo, release.Release, url.value
+
+			break
+		}
+	}
+
+	if tag == "" {
+		return nil
+	}
+
+	next := resolve.Next(owner, repo, tag)
+	if next == tag {
+		return nil
+	}
+
+	var ret []edit
+
+	for _, value := range append(urls, args["strip_prefix"]...) {
+		ret = append(ret, edit{literal: value, updated: replaceVersion(value.value, tag, next)})
+	}
+
+	archive = replaceVersion(archive, tag, next)
+
+	sum, err := fetcher.SHA256(archive)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"url": archive,
+		}).Warning("Cannot find the checksum of the new archive, leaving it as is")
+
+		return nil
+	}
+
+	for _, value := range args["sha256"] {
+		ret = append(ret, edit{literal: value, updated: sum})
+	}
+
+	raw, err := hex.DecodeString(sum)
+	if err != nil {
+		return nil
+	}
+
+	for _, value := range args["integrity"] {
+		ret = append(ret, edit{literal: value, updated: "sha256-" + base64.StdEncoding.EncodeToString(raw)})
+	}
+
+	return ret
+}
+
+// updateDep Update the `version` of a `bazel_dep` to the latest version of
+// the registry.
+func updateDep(args map[string][]literal, registry *Registry) []edit {
+	if len(args["name"]) != 1 || len(args["version"]) != 1 {
+		return nil
+	}
+
+	name := args["name"][0].value
+	version := args["version"][0]
+
+	available, err := registry.Versions(name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":    err,
+			"module": name,
+		}).Error("Cannot list module versions")
+
+		return nil
+	}
+
+	latest, err := versions.Latest(available)
+	if err != nil || !versions.Less(version.value, latest) {
+		return nil
+	}
+
+	return []edit{{literal: version, updated: latest}}
+}
+
+// Update Update the GitHub `http_archive` rules and the `bazel_dep` modules
+// of a Bazel `WORKSPACE` or `MODULE.bazel` file.
+func Update(bytesIn []byte, registry *Registry, fetcher *checksum.Fetcher, resolve gh.Resolver) (string, error) {
+	src := string(bytesIn)
+
+	found := calls(src, rules)
+	if len(found) == 0 {
+		return "", ErrorNoRules
+	}
+
+	var edits []edit
+
+	for _, rule := range found {
+		switch rule.name {
+		case "http_archive":
+			edits = append(edits, updateArchive(rule.args, fetcher, resolve)...)
+		case "bazel_dep":
+			edits = append(edits, updateDep(rule.args, registry)...)
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	for _, e := range edits {
+		src = src[0:e.start] + e.updated + src[e.end:]
+	}
+
+	return src, nil
+}
@@ -0,0 +1,159 @@
+package bazel
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testServer A local stand-in of the GitHub archives and of the Bazel
+// Central Registry.
+func testServer(t *testing.T) *httptest.Server {
+	files := map[string]string{
+		"/owner/rules_foo/archive/refs/tags/v1.3.0.tar.gz": "rules_foo 1.3.0",
+	}
+
+	modules := map[string]interface{}{
+		"rules_go": map[string]interface{}{
+			"versions":        []string{"0.41.0", "0.46.0", "0.47.0"},
+			"yanked_versions": map[string]string{"0.47.0": "broken"},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contents, ok := files[r.URL.Path]; ok {
+			fmt.Fprint(w, contents)
+
+			return
+		}
+
+		module := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/modules/"), "/metadata.json")
+		if metadata, ok := modules[module]; ok {
+			json.NewEncoder(w).Encode(metadata)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"owner/rules_foo": "v1.3.0",
+})
+
+func TestUpdate(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte("rules_foo 1.3.0"))
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "WORKSPACE",
+			in: heredoc.Doc(`
+				load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+				# http_archive(name = "commented")
+				http_archive(
+				    name = "rules_foo",
+				    sha256 = "0000000000000000000000000000000000000000000000000000000000000000",
+				    strip_prefix = "rules_foo-1.2.0",
+				    urls = [
+				        "https://mirror.example.com/rules_foo/v1.2.0.tar.gz",
+				        "https://github.com/owner/rules_foo/archive/refs/tags/v1.2.0.tar.gz",
+				    ],
+				)
+
+				http_archive(
+				    name = "other",
+				    url = "https://example.com/other-1.0.tar.gz",
+				)
+			`),
+			out: heredoc.Doc(`
+				load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+				# http_archive(name = "commented")
+				http_archive(
+				    name = "rules_foo",
+				    sha256 = "SHA256",
+				    strip_prefix = "rules_foo-1.3.0",
+				    urls = [
+				        "https://mirror.example.com/rules_foo/v1.3.0.tar.gz",
+				        "https://github.com/owner/rules_foo/archive/refs/tags/v1.3.0.tar.gz",
+				    ],
+				)
+
+				http_archive(
+				    name = "other",
+				    url = "https://example.com/other-1.0.tar.gz",
+				)
+			`),
+		},
+		{
+			name: "MODULE.bazel",
+			in: heredoc.Doc(`
+				module(name = "app", version = "1.0.0")
+
+				bazel_dep(name = "rules_go", version = "0.41.0", repo_name = "io_bazel_rules_go")
+				bazel_dep(name = "missing", version = "1.0.0")
+
+				http_archive = use_repo_rule("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+				http_archive(
+				    name = "rules_foo",
+				    integrity = "sha256-AAAA",
+				    strip_prefix = "rules_foo-1.2.0",
+				    url = "https://github.com/owner/rules_foo/archive/refs/tags/v1.2.0.tar.gz",
+				)
+			`),
+			out: heredoc.Doc(`
+				module(name = "app", version = "1.0.0")
+
+				bazel_dep(name = "rules_go", version = "0.46.0", repo_name = "io_bazel_rules_go")
+				bazel_dep(name = "missing", version = "1.0.0")
+
+				http_archive = use_repo_rule("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+				http_archive(
+				    name = "rules_foo",
+				    integrity = "INTEGRITY",
+				    strip_prefix = "rules_foo-1.3.0",
+				    url = "https://github.com/owner/rules_foo/archive/refs/tags/v1.3.0.tar.gz",
+				)
+			`),
+		},
+		{
+			name: "no rules",
+			in:   "workspace(name = \"app\")\n",
+			err:  ErrorNoRules,
+		},
+	}
+
+	replacer := strings.NewReplacer(
+		"SHA256", fmt.Sprintf("%x", sum),
+		"INTEGRITY", "sha256-"+base64.StdEncoding.EncodeToString(sum[:]),
+	)
+
+	for _, test := range cases {
+		out, err := Update([]byte(test.in), &Registry{URL: server.URL}, &checksum.Fetcher{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, replacer.Replace(test.out), out, test.name)
+	}
+}
@@ -0,0 +1,111 @@
+package cargo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultIndex = "https://index.crates.io"
+
+var (
+	ErrorCrateNotFound = errors.New("crate not found in the index")
+)
+
+// Index A client for the sparse registry index protocol of crates.io.
+type Index struct {
+	// URL overrides crates.io, for example to use a local index.
+	URL      string
+	Client   *http.Client
+	versions map[string][]string
+}
+
+func (i *Index) client() *http.Client {
+	if i.Client == nil {
+		return http.DefaultClient
+	}
+
+	return i.Client
+}
+
+// Path Return the path of a crate in the index, for example `se/rd/serde`
+// for `serde`.
+func Path(name string) string {
+	name = strings.ToLower(name)
+
+	switch len(name) {
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[0:1] + "/" + name
+	}
+
+	return name[0:2] + "/" + name[2:4] + "/" + name
+}
+
+// Versions List the versions of a crate that are not yanked.
+func (i *Index) Versions(name string) ([]string, error) {
+	if cached, ok := i.versions[name]; ok {
+		return cached, nil
+	}
+
+	base := i.URL
+	if base == "" {
+		base = DefaultIndex
+	}
+
+	address := strings.TrimSuffix(base, "/") + "/" + Path(name)
+
+	resp, err := i.client().Get(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrorCrateNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	var ret []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+
+	for scanner.Scan() {
+		var release struct {
+			Vers   string `json:"vers"`
+			Yanked bool   `json:"yanked"`
+		}
+
+		if json.Unmarshal(scanner.Bytes(), &release) != nil || release.Yanked {
+			continue
+		}
+
+		ret = append(ret, release.Vers)
+	}
+
+	if i.versions == nil {
+		i.versions = map[string][]string{}
+	}
+
+	i.versions[name] = ret
+
+	return ret, nil
+}
@@ -0,0 +1,76 @@
+package cargo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCrates The releases of the local index, with the yanked ones.
+var testCrates = map[string]map[string]bool{
+	"serde":     {"1.0.190": false, "1.0.197": false, "1.0.198": true},
+	"tokio":     {"1.35.1": false, "1.36.0": false},
+	"anyhow":    {"1.0.79": false, "1.0.81": false},
+	"rand":      {"0.8.5": false, "0.9.0-alpha.1": false},
+	"regex":     {"1.10.2": false, "1.10.4": false},
+	"cc":        {"1.0.83": false, "1.0.90": false},
+	"getrandom": {"0.2.11": false, "0.2.12": false},
+}
+
+// testIndex A local stand-in of the crates.io sparse index.
+func testIndex(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+		releases, ok := testCrates[name]
+		if !ok || r.URL.Path != "/"+Path(name) {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		for version, yanked := range releases {
+			fmt.Fprintf(w, `{"name":"%s","vers":"%s","deps":[],"cksum":"00","features":{},"yanked":%t}`+"\n", name, version, yanked)
+		}
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"owner/tool": "v0.4.0",
+})
+
+func TestPath(t *testing.T) {
+	var cases = []struct {
+		in  string
+		out string
+	}{
+		{in: "a", out: "1/a"},
+		{in: "cc", out: "2/cc"},
+		{in: "syn", out: "3/s/syn"},
+		{in: "Serde", out: "se/rd/serde"},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, Path(test.in), test.in)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	server := testIndex(t)
+	defer server.Close()
+
+	index := Index{URL: server.URL}
+
+	versions, err := index.Versions("serde")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"1.0.190", "1.0.197"}, versions)
+
+	_, err = index.Versions("missing")
+	assert.Equal(t, ErrorCrateNotFound, err)
+}
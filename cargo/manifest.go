@@ -0,0 +1,188 @@
+package cargo
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoDependencies = errors.New("no dependency tables found")
+)
+
+var (
+	tableRe  = regexp.MustCompile(`^\s*\[\[?([^\[\]]+)\]\]?\s*(#.*)?$`)
+	keyRe    = regexp.MustCompile(`^(\s*("[^"]+"|[A-Za-z0-9_-]+)\s*=\s*)(.*)$`)
+	depsRe   = regexp.MustCompile(`^(workspace\.|target\..+\.)?(dev-|build-)?dependencies$`)
+	crateRe  = regexp.MustCompile(`^(workspace\.|target\..+\.)?(dev-|build-)?dependencies\.("[^"]+"|[A-Za-z0-9_-]+)$`)
+	githubRe = regexp.MustCompile(`\bgit\s*=\s*"https://github\.com/([^/"]+)/([^/"]+?)(\.git)?/?"`)
+)
+
+// bumpable The operators of the version requirements that are updated.
+var bumpable = map[string]bool{
+	"":   true,
+	"^":  true,
+	"~":  true,
+	"=":  true,
+	">=": true,
+}
+
+func fieldRe(key string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + key + `\s*=\s*"([^"]*)"`)
+}
+
+// field Return the value of a `key = "value"` field of a table.
+func field(text, key string) string {
+	found := fieldRe(key).FindStringSubmatch(text)
+	if found == nil {
+		return ""
+	}
+
+	return found[1]
+}
+
+// replaceField Replace the value of a `key = "value"` field of a table.
+func replaceField(text, key string, update func(string) string) string {
+	found := fieldRe(key).FindStringSubmatchIndex(text)
+	if found == nil {
+		return text
+	}
+
+	return text[0:found[2]] + update(text[found[2]:found[3]]) + text[found[3]:]
+}
+
+// updateVersion Update a version requirement like `1.0` or `~1.2.3` to the
+// latest version of the crate, keeping its operator and precision.
+func updateVersion(name, requirement string, index *Index) string {
+	constraint, err := versions.ParseConstraint(requirement)
+	if err != nil || !bumpable[constraint.Operator] {
+		log.WithFields(log.Fields{
+			"crate":       name,
+			"requirement": requirement,
+		}).Debug("Requirement is not updated")
+
+		return requirement
+	}
+
+	available, err := index.Versions(name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"crate": name,
+		}).Error("Cannot list crate versions")
+
+		return requirement
+	}
+
+	latest, err := versions.Latest(available)
+	if err != nil || !versions.Less(constraint.Version, latest) {
+		return requirement
+	}
+
+	return constraint.Bump(latest)
+}
+
+// updateTable Update the fields of a dependency table, either an inline
+// table or a `[dependencies.name]` table. Renamed crates are looked up by
+// their `package` and GitHub dependencies have their `tag` updated.
+func updateTable(name, text string, index *Index, resolve gh.Resolver) string {
+	if pkg := field(text, "package"); pkg != "" {
+		name = pkg
+	}
+
+	if git := githubRe.FindStringSubmatch(text); git != nil {
+		return replaceField(text, "tag", func(tag string) string {
+			return resolve.Next(git[1], git[2], tag)
+		})
+	}
+
+	return replaceField(text, "version", func(version string) string {
+		return updateVersion(name, version, index)
+	})
+}
+
+// updateValue Update the value of a `name = ...` dependency, that is either
+// a version requirement or an inline table.
+func updateValue(name, value string, index *Index, resolve gh.Resolver) string {
+	trimmed := strings.TrimSpace(value)
+
+	if strings.HasPrefix(trimmed, "{") {
+		return updateTable(name, value, index, resolve)
+	}
+
+	if !strings.HasPrefix(trimmed, `"`) {
+		return value
+	}
+
+	start := strings.Index(value, `"`)
+
+	end := strings.Index(value[start+1:], `"`)
+	if end < 0 {
+		return value
+	}
+
+	end += start + 1
+
+	return value[0:start+1] + updateVersion(name, value[start+1:end], index) + value[end:]
+}
+
+// Update Update the dependencies of a `Cargo.toml`, including the
+// `[workspace.dependencies]`, the target specific ones and the
+// `[dependencies.name]` tables, keeping the formatting and the comments.
+func Update(bytesIn []byte, index *Index, resolve gh.Resolver) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	found := false
+
+	for i := 0; i < len(lines); i++ {
+		match := tableRe.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+
+		table := strings.TrimSpace(match[1])
+
+		if crate := crateRe.FindStringSubmatch(table); crate != nil {
+			found = true
+
+			// the table ends at the next table
+			end := i + 1
+			for end < len(lines) && !tableRe.MatchString(lines[end]) {
+				end++
+			}
+
+			text := updateTable(strings.Trim(crate[3], `"`), strings.Join(lines[i+1:end], "\n"), index, resolve)
+			copy(lines[i+1:end], strings.Split(text, "\n"))
+
+			i = end - 1
+
+			continue
+		}
+
+		if !depsRe.MatchString(table) {
+			continue
+		}
+
+		found = true
+
+		for i+1 < len(lines) && !tableRe.MatchString(lines[i+1]) {
+			i++
+
+			key := keyRe.FindStringSubmatch(lines[i])
+			if key == nil {
+				continue
+			}
+
+			lines[i] = key[1] + updateValue(strings.Trim(key[2], `"`), key[3], index, resolve)
+		}
+	}
+
+	if !found {
+		return "", ErrorNoDependencies
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,96 @@
+package cargo
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	server := testIndex(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "crate manifest",
+			in: heredoc.Doc(`
+				[package]
+				name = "app"
+				version = "0.1.0"
+
+				[dependencies]
+				serde = { version = "1.0.190", features = ["derive"] } # serialisation
+				tokio = "~1.35"
+				anyhow = "=1.0.79"
+				random = { package = "rand", version = "0.8" }
+				regex = "<1.10"
+				tool = { git = "https://github.com/owner/tool", tag = "v0.2.0" }
+				shared = { workspace = true }
+
+				[dependencies.cc]
+				# build helper
+				version = "1.0.83"
+
+				[target.'cfg(unix)'.dev-dependencies]
+				getrandom = "0.2.11"
+			`),
+			out: heredoc.Doc(`
+				[package]
+				name = "app"
+				version = "0.1.0"
+
+				[dependencies]
+				serde = { version = "1.0.197", features = ["derive"] } # serialisation
+				tokio = "~1.36"
+				anyhow = "=1.0.81"
+				random = { package = "rand", version = "0.8" }
+				regex = "<1.10"
+				tool = { git = "https://github.com/owner/tool", tag = "v0.4.0" }
+				shared = { workspace = true }
+
+				[dependencies.cc]
+				# build helper
+				version = "1.0.90"
+
+				[target.'cfg(unix)'.dev-dependencies]
+				getrandom = "0.2.12"
+			`),
+		},
+		{
+			name: "workspace",
+			in: heredoc.Doc(`
+				[workspace]
+				members = ["app"]
+
+				[workspace.dependencies]
+				serde = "1.0"
+				tokio = { version = "1.35.1", features = ["full"] }
+			`),
+			out: heredoc.Doc(`
+				[workspace]
+				members = ["app"]
+
+				[workspace.dependencies]
+				serde = "1.0"
+				tokio = { version = "1.36.0", features = ["full"] }
+			`),
+		},
+		{
+			name: "no dependencies",
+			in:   "[package]\nname = \"app\"\n",
+			err:  ErrorNoDependencies,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := Update([]byte(test.in), &Index{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
@@ -0,0 +1,173 @@
+package checksum
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const GithubURL = "https://github.com"
+
+var (
+	ErrorNotFound = errors.New("checksum not found")
+)
+
+var (
+	releaseRe = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/releases/download/([^/]+)/([^/?#]+)$`)
+	sha256Re  = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+)
+
+// Fetcher Find the sha256 checksums of release assets, from the checksum
+// files published with the release or by downloading the asset.
+type Fetcher struct {
+	// URL overrides https://github.com, for example to use a local mirror.
+	URL    string
+	Client *http.Client
+	sums   map[string]string
+}
+
+func (f *Fetcher) client() *http.Client {
+	if f.Client == nil {
+		return http.DefaultClient
+	}
+
+	return f.Client
+}
+
+// get Download a URL, replacing the GitHub host with the Fetcher URL.
+func (f *Fetcher) get(address string) (io.ReadCloser, error) {
+	if f.URL != "" && strings.HasPrefix(address, GithubURL+"/") {
+		address = strings.TrimSuffix(f.URL, "/") + strings.TrimPrefix(address, GithubURL)
+	}
+
+	resp, err := f.client().Get(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", address)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// checksumFiles The names of the checksum files that releases usually
+// publish next to their assets.
+func checksumFiles(repo, tag, asset string) []string {
+	return []string{
+		asset + ".sha256",
+		asset + ".sha256sum",
+		"checksums.txt",
+		"SHA256SUMS",
+		"sha256sums.txt",
+		fmt.Sprintf("%s_%s_checksums.txt", repo, strings.TrimPrefix(tag, "v")),
+	}
+}
+
+// parseSums Find the checksum of the asset in a checksum file, which either
+// has `<sha256>  <file>` lines or a single checksum.
+func parseSums(body []byte, asset string) (string, error) {
+	var single []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !sha256Re.MatchString(fields[0]) {
+			continue
+		}
+
+		if len(fields) == 1 {
+			single = append(single, fields[0])
+
+			continue
+		}
+
+		if path.Base(strings.TrimPrefix(fields[1], "*")) == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	if len(single) == 1 {
+		return strings.ToLower(single[0]), nil
+	}
+
+	return "", ErrorNotFound
+}
+
+// published Find the checksum of a GitHub release asset in the checksum
+// files of the release.
+func (f *Fetcher) published(address string) (string, error) {
+	found := releaseRe.FindStringSubmatch(address)
+	if found == nil {
+		return "", ErrorNotFound
+	}
+
+	repo, tag, asset := found[2], found[3], found[4]
+	base := strings.TrimSuffix(address, asset)
+
+	for _, file := range checksumFiles(repo, tag, asset) {
+		body, err := f.get(base + file)
+		if err != nil {
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(body)
+		body.Close()
+
+		if err != nil {
+			continue
+		}
+
+		sum, err := parseSums(contents, asset)
+		if err == nil {
+			return sum, nil
+		}
+	}
+
+	return "", ErrorNotFound
+}
+
+// SHA256 Return the sha256 checksum of the file of a URL.
+func (f *Fetcher) SHA256(address string) (string, error) {
+	if sum, ok := f.sums[address]; ok {
+		return sum, nil
+	}
+
+	sum, err := f.published(address)
+	if err != nil {
+		body, err := f.get(address)
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+
+		hash := sha256.New()
+
+		_, err = io.Copy(hash, body)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot download %s", address)
+		}
+
+		sum = fmt.Sprintf("%x", hash.Sum(nil))
+	}
+
+	if f.sums == nil {
+		f.sums = map[string]string{}
+	}
+
+	f.sums[address] = sum
+
+	return sum, nil
+}
@@ -0,0 +1,185 @@
+package checksum
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"mvdan.cc/xurls/v2"
+)
+
+// window The number of lines after a URL that are searched for its
+// checksum, for example for an `echo "<sha256>  file" | sha256sum -c`.
+const window = 5
+
+var (
+	variableRe = regexp.MustCompile(`\$[{(]?([A-Za-z_][A-Za-z0-9_]*)`)
+	sumRe      = regexp.MustCompile(`(^|[^0-9a-fA-F])[0-9a-fA-F]{64}([^0-9a-fA-F]|$)`)
+)
+
+// definitions Find the lines that define a shell, Makefile or Dockerfile
+// variable, like `TOOL_SHA256=...`, `TOOL_SHA256 := ...` or
+// `ARG TOOL_SHA256=...`.
+func definitions(lines []string, name string) []int {
+	re := regexp.MustCompile(
+		`^\s*(export\s+|ARG\s+|ENV\s+|readonly\s+|local\s+|declare\s+)?` + regexp.QuoteMeta(name) + `(\s*[?:+]?=|\s+\S)`,
+	)
+
+	var ret []int
+
+	for i, line := range lines {
+		if re.MatchString(line) {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// candidates Return the lines that can hold the checksum of a URL of the
+// line: the line itself, the next lines and the definitions of the
+// variables they use.
+func candidates(lines []string, line int) []int {
+	var ret []int
+
+	seen := map[int]bool{}
+
+	for i := line; i < len(lines) && i <= line+window; i++ {
+		if !seen[i] {
+			ret = append(ret, i)
+			seen[i] = true
+		}
+
+		for _, variable := range variableRe.FindAllStringSubmatch(lines[i], -1) {
+			for _, definition := range definitions(lines, variable[1]) {
+				if !seen[definition] {
+					ret = append(ret, definition)
+					seen[definition] = true
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
+// replace Replace the old checksum with the new one in the lines, keeping
+// the case of the old checksum.
+func replace(lines []string, indexes []int, oldSum, newSum string) {
+	for _, i := range indexes {
+		lines[i] = strings.ReplaceAll(lines[i], oldSum, newSum)
+		lines[i] = strings.ReplaceAll(lines[i], strings.ToUpper(oldSum), strings.ToUpper(newSum))
+	}
+}
+
+// Change A URL of a line that changed to a new URL.
+type Change struct {
+	Line   int
+	OldURL string
+	NewURL string
+}
+
+// Update Update the sha256 checksums of the URLs that changed between the
+// lines and the updated lines. The checksums are searched for in the line
+// of the URL, the next lines and the definitions of the variables that
+// these lines use, and only the checksum of the old URL is replaced.
+func Update(lines, updated []string, fetcher *Fetcher) []string {
+	var changes []Change
+
+	urls := xurls.Strict()
+
+	for i := range lines {
+		if i >= len(updated) || lines[i] == updated[i] {
+			continue
+		}
+
+		oldURLs := urls.FindAllString(lines[i], -1)
+		newURLs := urls.FindAllString(updated[i], -1)
+
+		if len(oldURLs) != len(newURLs) {
+			continue
+		}
+
+		for j := range oldURLs {
+			if oldURLs[j] != newURLs[j] {
+				changes = append(changes, Change{Line: i, OldURL: oldURLs[j], NewURL: newURLs[j]})
+			}
+		}
+	}
+
+	return UpdateURLs(updated, changes, fetcher)
+}
+
+// UpdateURLs Update the sha256 checksums of URLs that changed without their
+// text changing, like URLs built from variables, the same way as Update.
+func UpdateURLs(lines []string, changes []Change, fetcher *Fetcher) []string {
+	ret := append([]string{}, lines...)
+
+	for _, change := range changes {
+		updateChecksum(ret, candidates(ret, change.Line), change.OldURL, change.NewURL, fetcher)
+	}
+
+	return ret
+}
+
+// hasChecksum Check if any of the lines holds something that looks like a
+// sha256 checksum.
+func hasChecksum(lines []string, indexes []int) bool {
+	for _, i := range indexes {
+		if sumRe.MatchString(lines[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateChecksum Replace the checksum of the old URL with the checksum of
+// the new URL, if the checksum of the old URL is found in the lines.
+func updateChecksum(lines []string, indexes []int, oldURL, newURL string, fetcher *Fetcher) {
+	if !hasChecksum(lines, indexes) {
+		log.WithFields(log.Fields{
+			"url": oldURL,
+		}).Debug("No checksum near the URL")
+
+		return
+	}
+
+	oldSum, err := fetcher.SHA256(oldURL)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"url": oldURL,
+		}).Debug("Cannot find the checksum of the old URL")
+
+		return
+	}
+
+	found := false
+	for _, i := range indexes {
+		if strings.Contains(strings.ToLower(lines[i]), oldSum) {
+			found = true
+		}
+	}
+
+	if !found {
+		return
+	}
+
+	newSum, err := fetcher.SHA256(newURL)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"url": newURL,
+		}).Warning("Cannot find the checksum of the new URL, the old checksum is kept")
+
+		return
+	}
+
+	replace(lines, indexes, oldSum, newSum)
+
+	log.WithFields(log.Fields{
+		"url":    newURL,
+		"sha256": newSum,
+	}).Debug("Updated checksum")
+}
@@ -0,0 +1,186 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func sum(contents string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+}
+
+// testReleases A local stand-in of GitHub release downloads. v1.2.3 only
+// has its asset and v1.3.0 publishes a `checksums.txt` as well.
+func testReleases(t *testing.T) *httptest.Server {
+	files := map[string]string{
+		"/owner/tool/releases/download/v1.2.3/tool.tar.gz": "tool 1.2.3",
+		"/owner/tool/releases/download/v1.3.0/checksums.txt": fmt.Sprintf(
+			"%s  tool.zip\n%s  tool.tar.gz\n", sum("zip 1.3.0"), sum("tool 1.3.0"),
+		),
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprint(w, contents)
+	}))
+}
+
+func TestSHA256(t *testing.T) {
+	server := testReleases(t)
+	defer server.Close()
+
+	fetcher := Fetcher{URL: server.URL}
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  bool
+	}{
+		{
+			name: "downloaded asset",
+			in:   "https://github.com/owner/tool/releases/download/v1.2.3/tool.tar.gz",
+			out:  sum("tool 1.2.3"),
+		},
+		{
+			name: "published checksums",
+			in:   "https://github.com/owner/tool/releases/download/v1.3.0/tool.tar.gz",
+			out:  sum("tool 1.3.0"),
+		},
+		{
+			name: "missing asset",
+			in:   "https://github.com/owner/tool/releases/download/v1.3.0/missing.tar.gz",
+			err:  true,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := fetcher.SHA256(test.in)
+		assert.Equal(t, test.err, err != nil, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	server := testReleases(t)
+	defer server.Close()
+
+	oldURL := "https://github.com/owner/tool/releases/download/v1.2.3/tool.tar.gz"
+	newURL := "https://github.com/owner/tool/releases/download/v1.3.0/tool.tar.gz"
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "next lines",
+			in: heredoc.Doc(`
+				RUN curl -sLo tool.tar.gz OLD_URL \
+				    && echo "OLD_SUM  tool.tar.gz" | sha256sum -c
+			`),
+			out: heredoc.Doc(`
+				RUN curl -sLo tool.tar.gz NEW_URL \
+				    && echo "NEW_SUM  tool.tar.gz" | sha256sum -c
+			`),
+		},
+		{
+			name: "same line",
+			in:   "ADD --checksum=sha256:OLD_SUM OLD_URL /tmp/\n",
+			out:  "ADD --checksum=sha256:NEW_SUM NEW_URL /tmp/\n",
+		},
+		{
+			name: "named variable",
+			in: heredoc.Doc(`
+				TOOL_SHA256 := OLD_SUM
+				OTHER_SHA256 := 0000000000000000000000000000000000000000000000000000000000000000
+
+				tool:
+					curl -sLo tool.tar.gz OLD_URL
+					echo "$(TOOL_SHA256)  tool.tar.gz" | sha256sum -c
+			`),
+			out: heredoc.Doc(`
+				TOOL_SHA256 := NEW_SUM
+				OTHER_SHA256 := 0000000000000000000000000000000000000000000000000000000000000000
+
+				tool:
+					curl -sLo tool.tar.gz NEW_URL
+					echo "$(TOOL_SHA256)  tool.tar.gz" | sha256sum -c
+			`),
+		},
+	}
+
+	replacer := strings.NewReplacer(
+		"OLD_URL", oldURL,
+		"NEW_URL", newURL,
+		"OLD_SUM", sum("tool 1.2.3"),
+		"NEW_SUM", sum("tool 1.3.0"),
+	)
+
+	for _, test := range cases {
+		lines := strings.Split(replacer.Replace(test.in), "\n")
+		updated := strings.Split(strings.ReplaceAll(replacer.Replace(test.in), oldURL, newURL), "\n")
+
+		out := Update(lines, updated, &Fetcher{URL: server.URL})
+		assert.Equal(t, replacer.Replace(test.out), strings.Join(out, "\n"), test.name)
+	}
+}
+
+func TestUpdateWithoutChecksum(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	lines := []string{
+		"TOOL_VERSION := 1.2.3",
+		"RUN curl -sLo tool.tar.gz https://github.com/owner/tool/releases/download/v$(TOOL_VERSION)/tool.tar.gz",
+		"RUN curl -sLo other.tar.gz https://github.com/owner/other/releases/download/v1.2.3/other.tar.gz",
+	}
+	updated := []string{
+		lines[0],
+		lines[1],
+		"RUN curl -sLo other.tar.gz https://github.com/owner/other/releases/download/v1.3.0/other.tar.gz",
+	}
+
+	out := Update(lines, updated, &Fetcher{URL: server.URL})
+	assert.Equal(t, updated, out)
+	assert.Equal(t, 0, requests)
+}
+
+func TestUpdateURLs(t *testing.T) {
+	server := testReleases(t)
+	defer server.Close()
+
+	lines := []string{
+		"ARG TOOL_VERSION=v1.3.0",
+		"ARG TOOL_SHA256=" + sum("tool 1.2.3"),
+		"RUN curl -sLo tool.tar.gz https://github.com/owner/tool/releases/download/${TOOL_VERSION}/tool.tar.gz \\",
+		`    && echo "${TOOL_SHA256}  tool.tar.gz" | sha256sum -c`,
+	}
+
+	out := UpdateURLs(lines, []Change{
+		{
+			Line:   2,
+			OldURL: "https://github.com/owner/tool/releases/download/v1.2.3/tool.tar.gz",
+			NewURL: "https://github.com/owner/tool/releases/download/v1.3.0/tool.tar.gz",
+		},
+	}, &Fetcher{URL: server.URL})
+
+	assert.Equal(t, []string{lines[0], "ARG TOOL_SHA256=" + sum("tool 1.3.0"), lines[2], lines[3]}, out)
+}
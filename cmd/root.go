@@ -4,13 +4,31 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/annotation"
+	"github.com/mhristof/zoi/ansible"
+	"github.com/mhristof/zoi/bazel"
+	"github.com/mhristof/zoi/cargo"
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/docker"
 	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/helm"
+	"github.com/mhristof/zoi/homebrew"
 	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/nix"
+	"github.com/mhristof/zoi/npm"
 	"github.com/mhristof/zoi/precommit"
+	"github.com/mhristof/zoi/python"
+	"github.com/mhristof/zoi/submodule"
+	"github.com/mhristof/zoi/terraform"
+	"github.com/mhristof/zoi/tools"
+	"github.com/mhristof/zoi/variables"
+	"github.com/mhristof/zoi/versions"
+	"github.com/mhristof/zoi/vim"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
@@ -30,14 +48,120 @@ var rootCmd = &cobra.Command{
 		To pin versions for Docker files, run 'docker build' with
 			zoi -- docker build -t foo .
 		where 'docker build -t foo .' would be the command to build your
-		docker container.
+		docker container. The 'apk add' and 'apt-get install' packages of
+		the Dockerfile are pinned in place to the installed versions.
+		Podman and buildah builds are supported as well, for example
+			zoi -- podman build -t foo .
+
+		Alternatively, to pin versions without building the container, run
+			zoi --static-pins Dockerfile
+		which looks up the 'apk add' packages in the APKINDEX of the alpine
+		branch of the 'FROM' image and the 'apt-get install' packages in
+		the Packages index of the debian or ubuntu release. Packages that
+		are already pinned are updated to the latest version.
 
 		To update a file containing supported versions, feed it in as
 			zoi file.txt
-		and updated version of the file will be output to stdout.
+		and updated version of the file will be output to stdout. When a
+		GitHub release URL is updated, the sha256 checksum of its asset in
+		the same line, the next lines or a variable they use is updated
+		too, from the checksum files of the release or by downloading the
+		asset.
+
+		Dockerfiles have the tags of their 'FROM' images updated to the
+		newest tag of the same shape, for example '3.18-alpine' becomes
+		'3.19-alpine'. The same applies to the 'image:' fields of
+		docker-compose files and Kubernetes manifests, and to the kustomize
+		'images:' blocks.
+
+		With --pin-digests, the images of Dockerfiles, docker-compose files
+		and Kubernetes manifests are pinned to their digests instead, for
+		example 'node:20-alpine' becomes 'node:20.11.1-alpine@sha256:...'.
+
+		Terraform files have their 'required_providers' versions bumped
+		as well and, with --inplace, the '.terraform.lock.hcl' next to
+		them is updated with the new provider versions and hashes.
+
+		Helm 'Chart.yaml' files have their 'dependencies:' updated to the
+		newest chart version of their repository that --helm-policy
+		allows and, with --inplace, the 'Chart.lock' is updated too. With
+		--helm-values, the image tags of the 'values.yaml' are updated
+		and the 'appVersion' is bumped when the image has no tag.
+
+		Python 'requirements*.txt', 'pyproject.toml' and 'Pipfile' files
+		have their dependencies updated to the latest versions of PyPI,
+		or of --pypi-index, and their 'git+https://github.com/...@ref'
+		URLs updated to the next release. With --inplace, the files that
+		requirements files include with '-r' and '-c' are updated too.
+
+		The dependencies of 'package.json' files are updated to the latest
+		versions of the npm registry, or of --npm-registry, keeping their
+		'^' and '~' range operators. GitHub 'owner/repo#tag' specs are
+		updated to the next release.
+
+		The dependencies of 'Cargo.toml' files are updated to the latest
+		versions of the crates.io index, or of the sparse index at
+		--cargo-index, and the 'tag' of GitHub dependencies is updated to
+		the next release.
+
+		asdf '.tool-versions' and mise config files have their tools
+		updated to the latest GitHub tag of the tool. Tools that are not
+		known can be added to the 'tools:' mapping of the --config file,
+		for example
+			tools:
+			  mytool:
+			    repo: owner/mytool
+			    prefix: v
+
+		Bazel 'WORKSPACE' and 'MODULE.bazel' files have their GitHub
+		'http_archive' rules updated to the next release, along with their
+		'strip_prefix', 'sha256' and 'integrity', and their 'bazel_dep'
+		modules updated to the latest version of the Bazel Central
+		Registry, or of --bazel-registry.
+
+		Ansible Galaxy 'requirements.yml' files have the 'version' of their
+		roles and collections updated to the latest version of Ansible
+		Galaxy, or of --galaxy-server, and the 'version' of GitHub 'src'
+		entries updated to the next release.
+
+		In Makefiles, shell scripts and Dockerfiles, GitHub URLs that are
+		built from a version variable, like
+		'.../releases/download/v$(TOOL_VERSION)/...', have the definition of
+		the variable updated to the next release, including 'ARG' and 'ENV'
+		lines and defaults like '${TOOL_VERSION:-1.2.3}'.
+
+		Any version can be bound to a datasource with a 'zoi:' comment on
+		the line before it, for example
+			# zoi: datasource=github-tags depName=kubernetes/kubernetes versioning=semver
+			KUBECTL_VERSION=1.28.2
+		The datasources are 'github-tags' and 'github-releases', and the
+		only versioning is 'semver'.
+
+		Nix expressions have the 'rev' or 'tag' of their 'fetchFromGitHub'
+		calls moved to the next release, or commits to the commit of the
+		latest tag, along with their 'hash' and the 'version' that
+		'rev = "v${version}"' refers to. The 'github:' inputs of 'flake.lock'
+		are locked to the latest commit of their branch, like
+		'nix flake update' does.
+
+		Homebrew formulas have their GitHub 'url' stanzas, including the
+		ones of 'on_macos', 'on_linux' and the CPU blocks, moved to the
+		next release together, along with their 'sha256' and the 'version'
+		of the formula.
+
+		Vim files like '.vimrc' and 'init.vim' have the 'tag' and 'commit'
+		of their vim-plug 'Plug' lines updated, and lua files the 'tag',
+		'commit' and 'version' of their packer and lazy.nvim plugin specs.
+		Tags move to the next release and commits to the commit of the
+		latest tag.
+
+		For '.gitmodules' files, the GitHub submodules that are behind the
+		latest tag of their repository are listed and, with --inplace,
+		moved to the commit of the tag in the git index. Submodules that
+		track a branch with 'branch =' are left as they are.
 	`),
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) > 2 && args[0] == "docker" && args[1] == "build" {
+		if docker.IsBuild(args) {
 			return nil
 		}
 
@@ -45,6 +169,15 @@ var rootCmd = &cobra.Command{
 			return errors.New("File not provided")
 		}
 
+		policy, err := cmd.Flags().GetString("helm-policy")
+		if err != nil {
+			return err
+		}
+
+		if _, err := versions.ParsePolicy(policy); err != nil {
+			return errors.Wrapf(err, "invalid --helm-policy %s", policy)
+		}
+
 		for _, arg := range args {
 			if _, err := os.Stat(arg); os.IsNotExist(err) {
 				return errors.Wrap(err, "File not found")
@@ -56,11 +189,26 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
+		if docker.IsBuild(args) {
+			err := docker.Build(args)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"err": err,
+				}).Error("Cannot pin the Dockerfile packages")
+
+				os.Exit(1)
+			}
+
+			return
+		}
+
 		prefTags, err := cmd.Flags().GetBool("pref-tags")
 		if err != nil {
 			panic(err)
 		}
 
+		ghToken := getGithubToken()
+
 		byteLines, err := ioutil.ReadFile(args[0])
 		if err != nil {
 			log.WithFields(log.Fields{
@@ -75,6 +223,12 @@ var rootCmd = &cobra.Command{
 			panic(err)
 		}
 
+		if filepath.Base(args[0]) == submodule.File {
+			updateSubmodules(args[0], byteLines, inplace, ghToken)
+
+			return
+		}
+
 		if inplace {
 			out, err = os.Create(args[0])
 			if err != nil {
@@ -84,7 +238,71 @@ var rootCmd = &cobra.Command{
 			defer out.Close()
 		}
 
-		ghToken := getGithubToken()
+		if filepath.Ext(args[0]) == ".tf" {
+			byteLines = updateTerraform(cmd, args[0], byteLines, inplace)
+		}
+
+		if filepath.Base(args[0]) == helm.ChartFile {
+			byteLines = updateHelm(cmd, args[0], byteLines, inplace)
+		}
+
+		if base := filepath.Base(args[0]); python.IsRequirements(args[0]) || base == "pyproject.toml" || base == "Pipfile" {
+			byteLines = updatePython(cmd, args[0], byteLines, inplace, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if filepath.Base(args[0]) == "package.json" {
+			byteLines = updateNpm(cmd, byteLines, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if filepath.Base(args[0]) == "Cargo.toml" {
+			byteLines = updateCargo(cmd, byteLines, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if filepath.Base(args[0]) == tools.ToolVersionsFile || tools.IsMise(args[0]) {
+			byteLines = updateTools(cmd, args[0], byteLines, &gh.Client{Token: ghToken})
+		}
+
+		if bazel.IsBazel(args[0]) {
+			byteLines = updateBazel(cmd, byteLines, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if nix.IsNix(args[0]) {
+			byteLines = updateNix(byteLines, gh.NewResolver(prefTags, ghToken), ghToken)
+		}
+
+		if filepath.Base(args[0]) == nix.LockFile {
+			byteLines = updateFlakeLock(byteLines, ghToken)
+		}
+
+		if homebrew.IsFormula(args[0]) {
+			byteLines = updateFormula(byteLines, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if vim.IsPlugins(args[0]) {
+			byteLines = updateVim(args[0], byteLines, gh.NewResolver(prefTags, ghToken), ghToken)
+		}
+
+		if ansible.IsRequirements(args[0]) {
+			byteLines = updateAnsible(cmd, byteLines, gh.NewResolver(prefTags, ghToken))
+		}
+
+		if docker.IsDockerfile(args[0]) {
+			staticPins, err := cmd.Flags().GetBool("static-pins")
+			if err != nil {
+				panic(err)
+			}
+
+			if staticPins {
+				byteLines = staticDockerPins(cmd, byteLines)
+			} else {
+				byteLines = updateDockerfile(cmd, byteLines)
+			}
+		}
+
+		if ext := filepath.Ext(args[0]); ext == ".yml" || ext == ".yaml" {
+			byteLines = updateImages(cmd, byteLines)
+		}
+
 		precommitContents, err := precommit.Update(byteLines, prefTags, ghToken)
 		if err == nil {
 			fmt.Fprintf(out, "%s", precommitContents)
@@ -99,12 +317,579 @@ var rootCmd = &cobra.Command{
 		// lines ends up having one extra line at the end. Im sure there is a
 		// better fix, but meh.
 		llines := strings.Split(string(byteLines), "\n")
-		for _, line := range llines[0 : len(llines)-1] {
-			fmt.Fprintf(out, "%s\n", gh.Release(line, prefTags, ghToken))
+		llines = llines[0 : len(llines)-1]
+
+		updated := make([]string, len(llines))
+		for i, line := range llines {
+			updated[i] = gh.Release(line, prefTags, ghToken)
+		}
+
+		var changes []checksum.Change
+
+		if variables.IsScript(args[0]) {
+			updated, changes = variables.Update(args[0], updated, gh.NewResolver(prefTags, ghToken))
+		}
+
+		updated = annotation.Update(updated, annotation.NewResolvers(ghToken))
+
+		fetcher := &checksum.Fetcher{}
+		updated = checksum.Update(llines, updated, fetcher)
+
+		for _, line := range checksum.UpdateURLs(updated, changes, fetcher) {
+			fmt.Fprintf(out, "%s\n", line)
 		}
 	},
 }
 
+func updateTerraform(cmd *cobra.Command, file string, byteLines []byte, inplace bool) []byte {
+	registryURL, err := cmd.Flags().GetString("terraform-registry")
+	if err != nil {
+		panic(err)
+	}
+
+	platforms, err := cmd.Flags().GetStringSlice("terraform-platforms")
+	if err != nil {
+		panic(err)
+	}
+
+	registry := &terraform.Registry{URL: registryURL, Platforms: platforms}
+
+	contents, providers, err := terraform.Update(byteLines, registry)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No terraform providers to update")
+
+		return byteLines
+	}
+
+	lockFile := filepath.Join(filepath.Dir(file), terraform.LockFile)
+
+	lockBytes, err := ioutil.ReadFile(lockFile)
+	if err != nil || len(providers) == 0 {
+		return []byte(contents)
+	}
+
+	if !inplace {
+		log.WithFields(log.Fields{
+			"lockFile": lockFile,
+		}).Warning("Lock file is only updated with --inplace")
+
+		return []byte(contents)
+	}
+
+	lockContents, err := terraform.UpdateLock(lockBytes, providers, registry)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"lockFile": lockFile,
+		}).Error("Cannot update lock file")
+
+		return []byte(contents)
+	}
+
+	err = ioutil.WriteFile(lockFile, []byte(lockContents), 0644)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"lockFile": lockFile,
+		}).Error("Cannot write lock file")
+	}
+
+	return []byte(contents)
+}
+
+func updateHelm(cmd *cobra.Command, file string, byteLines []byte, inplace bool) []byte {
+	name, err := cmd.Flags().GetString("helm-policy")
+	if err != nil {
+		panic(err)
+	}
+
+	policy, err := versions.ParsePolicy(name)
+	if err != nil {
+		panic(err)
+	}
+
+	dir := filepath.Dir(file)
+
+	contents, resolved, err := helm.Update(byteLines, &helm.Repositories{Dir: dir}, policy)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No chart dependencies to update")
+
+		contents = string(byteLines)
+	}
+
+	if updateValues(cmd) {
+		contents = updateAppVersion(cmd, dir, contents, inplace)
+	}
+
+	lockFile := filepath.Join(dir, helm.LockFile)
+
+	lockBytes, err := ioutil.ReadFile(lockFile)
+	if err != nil || len(resolved) == 0 {
+		return []byte(contents)
+	}
+
+	if !inplace {
+		log.WithFields(log.Fields{
+			"lockFile": lockFile,
+		}).Warning("Lock file is only updated with --inplace")
+
+		return []byte(contents)
+	}
+
+	lockContents, err := helm.UpdateLock(lockBytes, []byte(contents), resolved)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"lockFile": lockFile,
+		}).Error("Cannot update lock file")
+
+		return []byte(contents)
+	}
+
+	err = ioutil.WriteFile(lockFile, []byte(lockContents), 0644)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"lockFile": lockFile,
+		}).Error("Cannot write lock file")
+	}
+
+	return []byte(contents)
+}
+
+func updateValues(cmd *cobra.Command) bool {
+	values, err := cmd.Flags().GetBool("helm-values")
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
+
+// updateAppVersion Update the image tags of the `values.yaml` next to the
+// chart and the `appVersion` of the chart.
+func updateAppVersion(cmd *cobra.Command, dir, contents string, inplace bool) string {
+	valuesFile := filepath.Join(dir, helm.ValuesFile)
+
+	valuesBytes, err := ioutil.ReadFile(valuesFile)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":        err,
+			"valuesFile": valuesFile,
+		}).Debug("No values file")
+
+		return contents
+	}
+
+	registry := dockerRegistry(cmd)
+
+	chart, err := helm.UpdateAppVersion([]byte(contents), valuesBytes, registry)
+	if err == nil {
+		contents = chart
+	}
+
+	if !inplace {
+		log.WithFields(log.Fields{
+			"valuesFile": valuesFile,
+		}).Warning("Values file is only updated with --inplace")
+
+		return contents
+	}
+
+	values, err := helm.UpdateValues(valuesBytes, registry)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":        err,
+			"valuesFile": valuesFile,
+		}).Error("Cannot update values file")
+
+		return contents
+	}
+
+	err = ioutil.WriteFile(valuesFile, []byte(values), 0644)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":        err,
+			"valuesFile": valuesFile,
+		}).Error("Cannot write values file")
+	}
+
+	return contents
+}
+
+func updatePython(cmd *cobra.Command, file string, byteLines []byte, inplace bool, resolve gh.Resolver) []byte {
+	indexURL, err := cmd.Flags().GetString("pypi-index")
+	if err != nil {
+		panic(err)
+	}
+
+	index := &python.Index{URL: indexURL}
+
+	if !python.IsRequirements(file) {
+		contents, err := python.UpdateTOML(byteLines, index, resolve)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err": err,
+			}).Debug("No python dependencies to update")
+
+			return byteLines
+		}
+
+		return []byte(contents)
+	}
+
+	contents, err := python.UpdateRequirements(byteLines, index, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot update requirements")
+
+		return byteLines
+	}
+
+	updateIncludes(file, byteLines, inplace, index, resolve, map[string]bool{file: true})
+
+	return []byte(contents)
+}
+
+// updateIncludes Update the files that a requirements file includes, and
+// the files that they include in turn.
+func updateIncludes(file string, byteLines []byte, inplace bool, index *python.Index, resolve gh.Resolver, seen map[string]bool) {
+	for _, include := range python.Includes(byteLines) {
+		include = filepath.Join(filepath.Dir(file), include)
+		if seen[include] {
+			continue
+		}
+
+		seen[include] = true
+
+		if !inplace {
+			log.WithFields(log.Fields{
+				"include": include,
+			}).Warning("Included requirements are only updated with --inplace")
+
+			continue
+		}
+
+		includeBytes, err := ioutil.ReadFile(include)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":     err,
+				"include": include,
+			}).Error("Cannot read included requirements")
+
+			continue
+		}
+
+		contents, err := python.UpdateRequirements(includeBytes, index, resolve)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":     err,
+				"include": include,
+			}).Error("Cannot update included requirements")
+
+			continue
+		}
+
+		err = ioutil.WriteFile(include, []byte(contents), 0644)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":     err,
+				"include": include,
+			}).Error("Cannot write included requirements")
+		}
+
+		updateIncludes(include, includeBytes, inplace, index, resolve, seen)
+	}
+}
+
+func updateNpm(cmd *cobra.Command, byteLines []byte, resolve gh.Resolver) []byte {
+	registryURL, err := cmd.Flags().GetString("npm-registry")
+	if err != nil {
+		panic(err)
+	}
+
+	contents, err := npm.Update(byteLines, &npm.Registry{URL: registryURL}, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No npm dependencies to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateCargo(cmd *cobra.Command, byteLines []byte, resolve gh.Resolver) []byte {
+	indexURL, err := cmd.Flags().GetString("cargo-index")
+	if err != nil {
+		panic(err)
+	}
+
+	contents, err := cargo.Update(byteLines, &cargo.Index{URL: indexURL}, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No crates to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateBazel(cmd *cobra.Command, byteLines []byte, resolve gh.Resolver) []byte {
+	registryURL, err := cmd.Flags().GetString("bazel-registry")
+	if err != nil {
+		panic(err)
+	}
+
+	contents, err := bazel.Update(byteLines, &bazel.Registry{URL: registryURL}, &checksum.Fetcher{}, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No Bazel rules to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateAnsible(cmd *cobra.Command, byteLines []byte, resolve gh.Resolver) []byte {
+	galaxyURL, err := cmd.Flags().GetString("galaxy-server")
+	if err != nil {
+		panic(err)
+	}
+
+	contents, err := ansible.Update(byteLines, &ansible.Galaxy{URL: galaxyURL}, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No Ansible roles or collections to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateNix(byteLines []byte, resolve gh.Resolver, ghToken string) []byte {
+	contents, err := nix.Update(byteLines, &nix.Archives{}, resolve, &gh.Client{Token: ghToken})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No fetchFromGitHub to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateFlakeLock(byteLines []byte, ghToken string) []byte {
+	contents, err := nix.UpdateLock(byteLines, &nix.Archives{}, &gh.Client{Token: ghToken})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No flake inputs to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateFormula(byteLines []byte, resolve gh.Resolver) []byte {
+	contents, err := homebrew.Update(byteLines, &checksum.Fetcher{}, resolve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No Homebrew formula to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func updateVim(file string, byteLines []byte, resolve gh.Resolver, ghToken string) []byte {
+	contents, err := vim.Update(file, byteLines, resolve, &gh.Client{Token: ghToken})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No vim plugins to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+// updateSubmodules Report the submodules that are behind their latest tag
+// and, with --inplace, move them to it.
+func updateSubmodules(file string, byteLines []byte, inplace bool, ghToken string) {
+	dir := filepath.Dir(file)
+	updates := submodule.Updates(dir, submodule.Parse(byteLines), &gh.Client{Token: ghToken})
+
+	for _, update := range updates {
+		fmt.Println(update)
+
+		if !inplace {
+			continue
+		}
+
+		err := submodule.Move(dir, update.Path, update.Commit)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":  err,
+				"path": update.Path,
+			}).Error("Cannot move the submodule")
+		}
+	}
+}
+
+// configFile Return the path of the config file, which defaults to
+// `~/.zoi.yaml` when it exists.
+func configFile(cmd *cobra.Command) string {
+	config, err := cmd.Flags().GetString("config")
+	if err != nil {
+		panic(err)
+	}
+
+	if config != "" {
+		return config
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	config = filepath.Join(home, ".zoi.yaml")
+	if _, err := os.Stat(config); err != nil {
+		return ""
+	}
+
+	return config
+}
+
+func updateTools(cmd *cobra.Command, file string, byteLines []byte, lister gh.TagLister) []byte {
+	upstreams, err := tools.LoadUpstreams(configFile(cmd))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot load the tools config")
+	}
+
+	update := tools.UpdateMise
+	if filepath.Base(file) == tools.ToolVersionsFile {
+		update = tools.UpdateToolVersions
+	}
+
+	contents, err := update(byteLines, upstreams, lister)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No tools to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func dockerRegistry(cmd *cobra.Command) *docker.Registry {
+	registryURL, err := cmd.Flags().GetString("docker-registry")
+	if err != nil {
+		panic(err)
+	}
+
+	return &docker.Registry{URL: registryURL}
+}
+
+func pinDigests(cmd *cobra.Command) bool {
+	pin, err := cmd.Flags().GetBool("pin-digests")
+	if err != nil {
+		panic(err)
+	}
+
+	return pin
+}
+
+func updateDockerfile(cmd *cobra.Command, byteLines []byte) []byte {
+	update := docker.UpdateDockerfile
+	if pinDigests(cmd) {
+		update = docker.PinDockerfile
+	}
+
+	contents, err := update(byteLines, dockerRegistry(cmd))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No Dockerfile images to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
+func staticDockerPins(cmd *cobra.Command, byteLines []byte) []byte {
+	apkFiles, err := cmd.Flags().GetStringSlice("apkindex")
+	if err != nil {
+		panic(err)
+	}
+
+	aptFiles, err := cmd.Flags().GetStringSlice("apt-packages")
+	if err != nil {
+		panic(err)
+	}
+
+	contents, err := docker.RewriteAlpine(byteLines, &docker.APKIndex{Files: apkFiles})
+	if err == nil {
+		byteLines = []byte(contents)
+	} else if err != docker.ErrorNoAlpineStage {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot pin the apk packages")
+	}
+
+	contents, err = docker.RewriteDebian(byteLines, &docker.DebianIndex{Files: aptFiles})
+	if err == nil {
+		byteLines = []byte(contents)
+	} else if err != docker.ErrorNoDebianStage {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot pin the apt packages")
+	}
+
+	return byteLines
+}
+
+func updateImages(cmd *cobra.Command, byteLines []byte) []byte {
+	update := docker.UpdateImages
+	if pinDigests(cmd) {
+		update = docker.PinImages
+	}
+
+	contents, err := update(byteLines, dockerRegistry(cmd))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No images to update")
+
+		return byteLines
+	}
+
+	return []byte(contents)
+}
+
 func getGithubToken() string {
 	ghToken := os.Getenv("GITHUB_READONLY_TOKEN")
 	if ghToken != "" {
@@ -139,6 +924,21 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("inplace", "i", false, "Inplace replacement of the target file")
 	rootCmd.PersistentFlags().BoolP("pref-tags", "t", true, "Prefer tags rather than releases when finding a new version")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Increase verbosity")
+	rootCmd.PersistentFlags().String("config", "", "Config file, defaults to ~/.zoi.yaml")
+	rootCmd.PersistentFlags().String("docker-registry", "", "Docker registry URL to use instead of the image registries")
+	rootCmd.PersistentFlags().Bool("static-pins", false, "Pin the apk and apt packages of a Dockerfile from the package indexes, without building it")
+	rootCmd.PersistentFlags().StringSlice("apkindex", []string{}, "Local APKINDEX files to use instead of the alpine mirror")
+	rootCmd.PersistentFlags().StringSlice("apt-packages", []string{}, "Local debian Packages files to use instead of the debian and ubuntu mirrors")
+	rootCmd.PersistentFlags().Bool("pin-digests", false, "Pin Docker images to their digests instead of updating their tags")
+	rootCmd.PersistentFlags().String("terraform-registry", "", "Terraform registry URL to use instead of the provider source hosts")
+	rootCmd.PersistentFlags().StringSlice("terraform-platforms", []string{}, "Platforms of the h1 hashes of terraform lock files, like linux_amd64, instead of the running platform")
+	rootCmd.PersistentFlags().String("pypi-index", "", "Python package index URL to use instead of PyPI")
+	rootCmd.PersistentFlags().String("npm-registry", "", "npm registry URL to use instead of registry.npmjs.org")
+	rootCmd.PersistentFlags().String("cargo-index", "", "Cargo sparse index URL to use instead of index.crates.io")
+	rootCmd.PersistentFlags().String("bazel-registry", "", "Bazel registry URL to use instead of the Bazel Central Registry")
+	rootCmd.PersistentFlags().String("galaxy-server", "", "Ansible Galaxy URL to use instead of galaxy.ansible.com")
+	rootCmd.PersistentFlags().String("helm-policy", string(versions.PolicyMajor), "Largest Helm chart dependency update allowed, one of major, minor or patch")
+	rootCmd.PersistentFlags().Bool("helm-values", false, "Update the image tags of the values.yaml next to a Helm chart and its appVersion")
 }
 
 // Execute The main function for the root command.
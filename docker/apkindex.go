@@ -0,0 +1,268 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+const DefaultAlpineMirror = "https://dl-cdn.alpinelinux.org/alpine"
+
+var (
+	ErrorNoAPKIndex    = errors.New("APKINDEX not found in archive")
+	ErrorNotAlpine     = errors.New("image is not an alpine image")
+	ErrorNoAlpineStage = errors.New("no alpine stage found")
+)
+
+// APKIndex Package versions from the APKINDEX files of an Alpine mirror.
+type APKIndex struct {
+	// Mirror defaults to DefaultAlpineMirror.
+	Mirror string
+	// Arch defaults to x86_64.
+	Arch string
+	// Repositories defaults to main and community.
+	Repositories []string
+	// Files overrides the mirror with local APKINDEX or APKINDEX.tar.gz
+	// files, for example to work offline.
+	Files  []string
+	Client *http.Client
+
+	cache map[string]map[string]string
+}
+
+// AlpineBranch Find the Alpine branch of an image, for example `v3.19` for
+// `alpine:3.19.1`.
+func AlpineBranch(image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Path != "library/alpine" {
+		return "", ErrorNotAlpine
+	}
+
+	switch ref.Tag {
+	case "", "latest":
+		return "latest-stable", nil
+	case "edge":
+		return "edge", nil
+	}
+
+	parts := strings.Split(ref.Tag, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("cannot find the alpine branch of %s", image)
+	}
+
+	return fmt.Sprintf("v%s.%s", parts[0], parts[1]), nil
+}
+
+// Packages Return the latest version of every package of a branch.
+func (a *APKIndex) Packages(branch string) (map[string]string, error) {
+	if a.cache == nil {
+		a.cache = map[string]map[string]string{}
+	}
+
+	if packages, ok := a.cache[branch]; ok {
+		return packages, nil
+	}
+
+	packages := map[string]string{}
+
+	for _, index := range a.indexes(branch) {
+		data, err := readIndex(a.Client, index)
+		if err != nil {
+			return nil, err
+		}
+
+		err = parseAPKIndex(data, packages)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s", index)
+		}
+	}
+
+	a.cache[branch] = packages
+
+	return packages, nil
+}
+
+func (a *APKIndex) indexes(branch string) []string {
+	if len(a.Files) > 0 {
+		return a.Files
+	}
+
+	mirror, arch, repositories := a.Mirror, a.Arch, a.Repositories
+	if mirror == "" {
+		mirror = DefaultAlpineMirror
+	}
+
+	if arch == "" {
+		arch = "x86_64"
+	}
+
+	if len(repositories) == 0 {
+		repositories = []string{"main", "community"}
+	}
+
+	var ret []string
+	for _, repository := range repositories {
+		ret = append(ret, fmt.Sprintf("%s/%s/%s/%s/APKINDEX.tar.gz",
+			strings.TrimSuffix(mirror, "/"), branch, repository, arch,
+		))
+	}
+
+	return ret
+}
+
+// readIndex Read a package index either from a local file or a URL.
+func readIndex(client *http.Client, index string) ([]byte, error) {
+	if !strings.HasPrefix(index, "http://") && !strings.HasPrefix(index, "https://") {
+		return ioutil.ReadFile(index)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	log.WithFields(log.Fields{
+		"index": index,
+	}).Debug("Downloading package index")
+
+	resp, err := client.Get(index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot download %s", index)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download %s: %s", index, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseAPKIndex Parse an APKINDEX, either compressed or not, keeping the
+// greatest version of every package.
+func parseAPKIndex(data []byte, packages map[string]string) error {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		index, err := extractAPKIndex(data)
+		if err != nil {
+			return err
+		}
+
+		data = index
+	}
+
+	var name, version string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+
+		if !more || line == "" {
+			if name != "" && version != "" {
+				if current, ok := packages[name]; !ok || tagLess(current, version) {
+					packages[name] = version
+				}
+			}
+
+			name, version = "", ""
+
+			if !more {
+				break
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "P:"):
+			name = line[2:]
+		case strings.HasPrefix(line, "V:"):
+			version = line[2:]
+		}
+	}
+
+	return scanner.Err()
+}
+
+// extractAPKIndex Extract the APKINDEX file out of an APKINDEX.tar.gz, which
+// is a concatenation of the signature and the index gzip streams.
+func extractAPKIndex(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	archive := tar.NewReader(gz)
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil, ErrorNoAPKIndex
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Name == "APKINDEX" {
+			return ioutil.ReadAll(archive)
+		}
+	}
+}
+
+// stageTracker Track the suite of the current Dockerfile stage, like the
+// alpine branch or the debian codename of its `FROM` image. Stages that
+// build on top of earlier stages inherit their suite.
+type stageTracker struct {
+	suiteOf func(string) (string, error)
+	stages  map[string]string
+	suite   string
+	found   bool
+}
+
+func newStageTracker(suiteOf func(string) (string, error)) *stageTracker {
+	return &stageTracker{
+		suiteOf: suiteOf,
+		stages:  map[string]string{},
+	}
+}
+
+// from Update the current suite if the line is a `FROM` instruction.
+func (s *stageTracker) from(line string) bool {
+	froms := ParseFrom([]string{line})
+	if len(froms) != 1 {
+		return false
+	}
+
+	suite, err := s.suiteOf(froms[0].Image)
+	if stageSuite, ok := s.stages[strings.ToLower(froms[0].Image)]; ok {
+		suite, err = stageSuite, nil
+	}
+
+	if err != nil {
+		suite = ""
+	}
+
+	if froms[0].Stage != "" {
+		s.stages[strings.ToLower(froms[0].Stage)] = suite
+	}
+
+	s.suite = suite
+	s.found = s.found || suite != ""
+
+	return true
+}
@@ -0,0 +1,119 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func slurp(t *testing.T, file string) []byte {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes
+}
+
+// apkIndexTarGz Create an APKINDEX.tar.gz like the ones alpine mirrors
+// serve, with a signature gzip stream followed by the index gzip stream.
+func apkIndexTarGz(t *testing.T, index []byte) []byte {
+	buf := new(bytes.Buffer)
+
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{name: ".SIGN.RSA.alpine-devel@lists.alpinelinux.org-6165ee59.rsa.pub", data: []byte("signature")},
+		{name: "DESCRIPTION", data: []byte("v3.19.0-1-g1234")},
+		{name: "APKINDEX", data: index},
+	} {
+		gz := gzip.NewWriter(buf)
+		archive := tar.NewWriter(gz)
+
+		err := archive.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data))})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = archive.Write(file.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		archive.Flush()
+		gz.Close()
+	}
+
+	return buf.Bytes()
+}
+
+func TestAlpineBranch(t *testing.T) {
+	var cases = []struct {
+		name  string
+		image string
+		out   string
+		err   bool
+	}{
+		{name: "patch release", image: "alpine:3.19.1", out: "v3.19"},
+		{name: "minor release", image: "docker.io/library/alpine:3.18", out: "v3.18"},
+		{name: "no tag", image: "alpine", out: "latest-stable"},
+		{name: "edge", image: "alpine:edge", out: "edge"},
+		{name: "major only", image: "alpine:3", err: true},
+		{name: "not alpine", image: "debian:12", err: true},
+	}
+
+	for _, test := range cases {
+		branch, err := AlpineBranch(test.image)
+		assert.Equal(t, test.err, err != nil, test.name)
+		assert.Equal(t, test.out, branch, test.name)
+	}
+}
+
+func TestAPKIndexPackages(t *testing.T) {
+	plain := slurp(t, "../test/fixtures/APKINDEX")
+	compressed := apkIndexTarGz(t, plain)
+
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write(compressed)
+	}))
+	defer server.Close()
+
+	expected := map[string]string{
+		"curl": "8.5.0-r0",
+		"htop": "3.2.2-r1",
+		"git":  "2.43.0-r10",
+	}
+
+	index := APKIndex{Mirror: server.URL}
+	packages, err := index.Packages("v3.19")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, packages)
+	assert.Equal(t, []string{
+		"/v3.19/main/x86_64/APKINDEX.tar.gz",
+		"/v3.19/community/x86_64/APKINDEX.tar.gz",
+	}, requested)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "APKINDEX.tar.gz")
+
+	err = ioutil.WriteFile(file, compressed, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index = APKIndex{Files: []string{file}}
+	packages, err = index.Packages("v3.19")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, packages)
+}
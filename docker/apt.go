@@ -0,0 +1,277 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultDebianMirror         = "http://deb.debian.org/debian"
+	DefaultDebianSecurityMirror = "http://security.debian.org/debian-security"
+	DefaultUbuntuMirror         = "http://archive.ubuntu.com/ubuntu"
+)
+
+var (
+	ErrorNotDebian     = errors.New("image is not a debian or ubuntu image")
+	ErrorNoDebianStage = errors.New("no debian or ubuntu stage found")
+)
+
+var debianCodenames = map[string]string{
+	"9":  "stretch",
+	"10": "buster",
+	"11": "bullseye",
+	"12": "bookworm",
+	"13": "trixie",
+}
+
+var ubuntuCodenames = map[string]string{
+	"18.04": "bionic",
+	"20.04": "focal",
+	"22.04": "jammy",
+	"24.04": "noble",
+}
+
+// DebianSuite Find the suite of a debian or ubuntu image in the form of
+// `distribution/codename`, for example `debian/bookworm` for
+// `debian:12-slim`.
+func DebianSuite(image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	tag := strings.TrimSuffix(ref.Tag, "-slim")
+
+	switch ref.Path {
+	case "library/debian":
+		if tag == "" || tag == "latest" {
+			return "debian/stable", nil
+		}
+
+		if codename, ok := debianCodenames[strings.Split(tag, ".")[0]]; ok {
+			return "debian/" + codename, nil
+		}
+
+		return "debian/" + strings.Split(tag, "-")[0], nil
+	case "library/ubuntu":
+		if codename, ok := ubuntuCodenames[tag]; ok {
+			return "ubuntu/" + codename, nil
+		}
+
+		if tag == "" || tag == "latest" || strings.IndexAny(tag, "0123456789") == 0 {
+			return "", fmt.Errorf("cannot find the ubuntu codename of %s", image)
+		}
+
+		return "ubuntu/" + strings.Split(tag, "-")[0], nil
+	}
+
+	return "", ErrorNotDebian
+}
+
+// DebianIndex Package versions from the `Packages` indexes of debian and
+// ubuntu mirrors.
+type DebianIndex struct {
+	// DebianMirror defaults to DefaultDebianMirror.
+	DebianMirror string
+	// DebianSecurityMirror defaults to DefaultDebianSecurityMirror.
+	DebianSecurityMirror string
+	// UbuntuMirror defaults to DefaultUbuntuMirror.
+	UbuntuMirror string
+	// Arch defaults to amd64.
+	Arch string
+	// Files overrides the mirrors with local Packages or Packages.gz files,
+	// for example to work offline.
+	Files  []string
+	Client *http.Client
+
+	cache map[string]map[string]string
+}
+
+// Packages Return the latest version of every package of a suite, as
+// returned by DebianSuite.
+func (d *DebianIndex) Packages(suite string) (map[string]string, error) {
+	if d.cache == nil {
+		d.cache = map[string]map[string]string{}
+	}
+
+	if packages, ok := d.cache[suite]; ok {
+		return packages, nil
+	}
+
+	packages := map[string]string{}
+
+	for _, index := range d.indexes(suite) {
+		data, err := readIndex(d.Client, index)
+		if err != nil {
+			return nil, err
+		}
+
+		err = parsePackages(data, packages)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s", index)
+		}
+	}
+
+	d.cache[suite] = packages
+
+	return packages, nil
+}
+
+// debianSecuritySuite The suite of the security updates of a debian
+// codename, which was `<codename>/updates` up to buster.
+func debianSecuritySuite(codename string) string {
+	switch codename {
+	case "jessie", "stretch", "buster":
+		return codename + "/updates"
+	}
+
+	return codename + "-security"
+}
+
+// indexes Return the Packages indexes of a suite, including the indexes of
+// its `-updates` and `-security` suites, as the images are built with them.
+func (d *DebianIndex) indexes(suite string) []string {
+	if len(d.Files) > 0 {
+		return d.Files
+	}
+
+	arch := d.Arch
+	if arch == "" {
+		arch = "amd64"
+	}
+
+	parts := strings.SplitN(suite, "/", 2)
+	codename := parts[1]
+
+	type dist struct {
+		mirror   string
+		codename string
+	}
+
+	var dists []dist
+	var components []string
+
+	switch parts[0] {
+	case "ubuntu":
+		mirror := d.UbuntuMirror
+		if mirror == "" {
+			mirror = DefaultUbuntuMirror
+		}
+
+		components = []string{"main", "universe"}
+		dists = []dist{
+			{mirror, codename},
+			{mirror, codename + "-updates"},
+			{mirror, codename + "-security"},
+		}
+	default:
+		mirror, security := d.DebianMirror, d.DebianSecurityMirror
+		if mirror == "" {
+			mirror = DefaultDebianMirror
+		}
+
+		if security == "" {
+			security = DefaultDebianSecurityMirror
+		}
+
+		components = []string{"main"}
+		dists = []dist{{mirror, codename}}
+
+		// unstable has no updates and no security support
+		if codename != "sid" && codename != "unstable" {
+			dists = append(dists,
+				dist{mirror, codename + "-updates"},
+				dist{security, debianSecuritySuite(codename)},
+			)
+		}
+	}
+
+	var ret []string
+
+	for _, dist := range dists {
+		for _, component := range components {
+			ret = append(ret, fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages.gz",
+				strings.TrimSuffix(dist.mirror, "/"), dist.codename, component, arch,
+			))
+		}
+	}
+
+	return ret
+}
+
+// parsePackages Parse a debian `Packages` index, either compressed or not,
+// keeping the greatest version of every package.
+func parsePackages(data []byte, packages map[string]string) error {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		data, err = ioutil.ReadAll(gz)
+		if err != nil {
+			return err
+		}
+	}
+
+	var name, version string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+
+		if !more || line == "" {
+			if name != "" && version != "" {
+				if current, ok := packages[name]; !ok || dpkgLess(current, version) {
+					packages[name] = version
+				}
+			}
+
+			name, version = "", ""
+
+			if !more {
+				break
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Package: "):
+			name = strings.TrimPrefix(line, "Package: ")
+		case strings.HasPrefix(line, "Version: "):
+			version = strings.TrimPrefix(line, "Version: ")
+		}
+	}
+
+	return scanner.Err()
+}
+
+// installedDebian Find the installed packages from the `Setting up` lines
+// of the build output.
+func installedDebian(lines []string) map[string]string {
+	ret := map[string]string{}
+
+	for _, line := range lines {
+		parts := strings.Fields(line)
+
+		// Setting up curl (7.88.1-10+deb12u5) ...
+		if len(parts) < 4 || parts[0] != "Setting" || parts[1] != "up" {
+			continue
+		}
+
+		ret[strings.Split(parts[2], ":")[0]] = trimParens(parts[3])
+	}
+
+	return ret
+}
@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebianSuite(t *testing.T) {
+	var cases = []struct {
+		name  string
+		image string
+		out   string
+		err   bool
+	}{
+		{name: "debian version", image: "debian:12.5", out: "debian/bookworm"},
+		{name: "debian slim codename", image: "debian:bookworm-slim", out: "debian/bookworm"},
+		{name: "debian latest", image: "debian", out: "debian/stable"},
+		{name: "ubuntu version", image: "ubuntu:22.04", out: "ubuntu/jammy"},
+		{name: "ubuntu codename", image: "ubuntu:noble-20240225", out: "ubuntu/noble"},
+		{name: "unknown ubuntu version", image: "ubuntu:16.04", err: true},
+		{name: "not debian", image: "alpine:3.19", err: true},
+	}
+
+	for _, test := range cases {
+		suite, err := DebianSuite(test.image)
+		assert.Equal(t, test.err, err != nil, test.name)
+		assert.Equal(t, test.out, suite, test.name)
+	}
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	gz.Write(data)
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+func TestDebianIndexPackages(t *testing.T) {
+	packages := gzipped(t, slurp(t, "../test/fixtures/Packages"))
+	security := gzipped(t, []byte(heredoc.Doc(`
+		Package: curl
+		Version: 7.88.1-10+deb12u6
+
+		Package: git
+		Version: 2.0-1
+
+		Package: ca-certificates
+		Version: 20230311~deb12u1
+	`)))
+
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+
+		if strings.Contains(r.URL.Path, "-security/") {
+			w.Write(security)
+
+			return
+		}
+
+		w.Write(packages)
+	}))
+	defer server.Close()
+
+	var cases = []struct {
+		name      string
+		index     DebianIndex
+		suite     string
+		packages  map[string]string
+		requested []string
+	}{
+		{
+			name:  "ubuntu",
+			index: DebianIndex{UbuntuMirror: server.URL + "/ubuntu"},
+			suite: "ubuntu/jammy",
+			packages: map[string]string{
+				"curl":            "7.88.1-10+deb12u6",
+				"ca-certificates": "20230311",
+				"git":             "1:2.39.2-1.1",
+			},
+			requested: []string{
+				"/ubuntu/dists/jammy/main/binary-amd64/Packages.gz",
+				"/ubuntu/dists/jammy/universe/binary-amd64/Packages.gz",
+				"/ubuntu/dists/jammy-updates/main/binary-amd64/Packages.gz",
+				"/ubuntu/dists/jammy-updates/universe/binary-amd64/Packages.gz",
+				"/ubuntu/dists/jammy-security/main/binary-amd64/Packages.gz",
+				"/ubuntu/dists/jammy-security/universe/binary-amd64/Packages.gz",
+			},
+		},
+		{
+			name: "debian",
+			index: DebianIndex{
+				DebianMirror:         server.URL + "/debian",
+				DebianSecurityMirror: server.URL + "/debian-security",
+			},
+			suite: "debian/bookworm",
+			packages: map[string]string{
+				"curl":            "7.88.1-10+deb12u6",
+				"ca-certificates": "20230311",
+				"git":             "1:2.39.2-1.1",
+			},
+			requested: []string{
+				"/debian/dists/bookworm/main/binary-amd64/Packages.gz",
+				"/debian/dists/bookworm-updates/main/binary-amd64/Packages.gz",
+				"/debian-security/dists/bookworm-security/main/binary-amd64/Packages.gz",
+			},
+		},
+		{
+			name: "debian before bullseye",
+			index: DebianIndex{
+				DebianMirror:         server.URL + "/debian",
+				DebianSecurityMirror: server.URL + "/debian-security",
+			},
+			suite: "debian/buster",
+			packages: map[string]string{
+				"curl":            "7.88.1-10+deb12u6",
+				"ca-certificates": "20230311",
+				"git":             "1:2.39.2-1.1",
+			},
+			requested: []string{
+				"/debian/dists/buster/main/binary-amd64/Packages.gz",
+				"/debian/dists/buster-updates/main/binary-amd64/Packages.gz",
+				"/debian-security/dists/buster/updates/main/binary-amd64/Packages.gz",
+			},
+		},
+		{
+			name:  "debian unstable",
+			index: DebianIndex{DebianMirror: server.URL + "/debian"},
+			suite: "debian/sid",
+			packages: map[string]string{
+				"curl":            "7.88.1-10+deb12u5",
+				"ca-certificates": "20230311",
+				"git":             "1:2.39.2-1.1",
+			},
+			requested: []string{
+				"/debian/dists/sid/main/binary-amd64/Packages.gz",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		requested = nil
+
+		out, err := test.index.Packages(test.suite)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.packages, out, test.name)
+		assert.Equal(t, test.requested, requested, test.name)
+	}
+}
+
+func TestDebian(t *testing.T) {
+	in := heredoc.Doc(`
+		Step 1/2 : FROM debian:bookworm
+		Step 2/2 : RUN apt-get update && apt-get install -y curl htop=3.2.2-1
+		 ---> Running in 8f1d2c3b4a5e
+		Get:1 http://deb.debian.org/debian bookworm InRelease [151 kB]
+		Setting up libcurl4:amd64 (7.88.1-10+deb12u5) ...
+		Setting up curl (7.88.1-10+deb12u5) ...
+		Setting up htop (3.2.2-1) ...
+		Processing triggers for libc-bin (2.36-9+deb12u4) ...
+	`)
+
+	lines := strings.Split(in, "\n")
+	assert.Equal(t,
+		"FROM debian:bookworm\nRUN apt-get update && apt-get install -y curl=7.88.1-10+deb12u5 htop=3.2.2-1\n",
+		pinInstalled(t, buildDockerfile(lines), installedDebian(lines), aptWords),
+	)
+}
@@ -3,96 +3,321 @@ package docker
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
-	"sort"
+	"path/filepath"
+	"regexp"
 	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
 )
 
-func Build(args []string) {
-	command := fmt.Sprintf("%s %s --no-cache %s",
-		args[0],
-		args[1],
-		strings.Join(args[2:], " "),
-	)
+// Build Run the build without the cache and pin the `apk add` and
+// `apt-get install` packages of the Dockerfile to the versions that were
+// installed during the build.
+func Build(args []string) error {
+	prefix, rest, ok := splitBuild(args)
+	if !ok {
+		return ErrorUnknownBuilder
+	}
 
-	fmt.Println(fmt.Sprintf("command: %+v", command))
+	command := buildCommand(prefix, rest)
 
-	fmt.Println(alpine(strings.Split(bash(command), "\n")))
+	log.WithFields(log.Fields{
+		"command": command,
+	}).Debug("Building")
 
-}
+	output, err := run(command, BuildOutput)
+	if err != nil {
+		return err
+	}
 
-func alpine(lines []string) string {
-	var packages []string
-	allPackages := map[string]string{}
+	lines := buildLines(output)
+	dockerfile := dockerfilePath(rest)
 
-	for _, line := range lines {
-		parts := strings.Split(line, " ")
+	bytesIn, err := ioutil.ReadFile(dockerfile)
+	if err != nil {
+		return errors.Wrap(err, "cannot read Dockerfile")
+	}
 
-		packages = append(packages, extractAlpinePackages(parts)...)
+	// the build output does not say which stage installed a package, so
+	// the stages are pinned with the versions installed by the package
+	// manager of their distribution.
+	anyStage := func(string) (string, error) { return "build", nil }
 
-		// (3/3) Installing htop (2.2.0-r0)
-		if len(parts) < 2 || parts[1] != "Installing" {
-			continue
+	contents := string(bytesIn)
+	for _, pin := range []struct {
+		extract   func([]word) []word
+		installed map[string]string
+	}{
+		{alpineWords, installedAlpine(lines)},
+		{aptWords, installedDebian(lines)},
+	} {
+		installed := pin.installed
+		packagesOf := func(string) (map[string]string, error) { return installed, nil }
+
+		contents, _, err = rewritePackages([]byte(contents), anyStage, packagesOf, pin.extract)
+		if err != nil {
+			return err
 		}
+	}
 
-		allPackages[parts[2]] = sanitiseAlpineVersion(parts[3])
+	log.WithFields(log.Fields{
+		"dockerfile": dockerfile,
+	}).Info("Pinning packages")
+
+	return ioutil.WriteFile(dockerfile, []byte(contents), 0644)
+}
+
+// buildCommands The build commands of the supported builders.
+var buildCommands = map[string][][]string{
+	"docker":  {{"build"}, {"buildx", "build"}, {"image", "build"}},
+	"podman":  {{"build"}, {"buildx", "build"}, {"image", "build"}},
+	"buildah": {{"bud"}, {"build"}, {"build-using-dockerfile"}},
+}
+
+// splitBuild Split a build command like `podman build -t foo .` into the
+// builder command, `podman build`, and its arguments.
+func splitBuild(args []string) ([]string, []string, bool) {
+	if len(args) == 0 {
+		return nil, nil, false
 	}
 
-	requested := map[string]string{}
+	for _, subcommand := range buildCommands[filepath.Base(args[0])] {
+		if len(args) < len(subcommand)+2 {
+			continue
+		}
+
+		match := true
+		for i, word := range subcommand {
+			match = match && args[i+1] == word
+		}
 
-	for _, pack := range packages {
-		if value, ok := allPackages[pack]; ok == true {
-			requested[pack] = value
+		if match {
+			return args[0 : len(subcommand)+1], args[len(subcommand)+1:], true
 		}
+	}
+
+	return nil, nil, false
+}
+
+// IsBuild Check if the arguments are a docker, podman or buildah build
+// command.
+func IsBuild(args []string) bool {
+	_, _, ok := splitBuild(args)
+
+	return ok
+}
 
+// buildCommand Add the arguments to build without the cache and, for
+// BuildKit, with plain progress output so that the package installation
+// lines are printed. Podman and buildah always print them.
+func buildCommand(prefix, args []string) []string {
+	command := append(append([]string{}, prefix...), "--no-cache")
+
+	progress := filepath.Base(prefix[0]) != "docker"
+	for _, arg := range args {
+		progress = progress || arg == "--progress" || strings.HasPrefix(arg, "--progress=")
 	}
 
-	var ret []string
-	for k, v := range requested {
-		ret = append(ret, fmt.Sprintf("%s=%s", k, v))
+	if !progress {
+		command = append(command, "--progress=plain")
 	}
 
-	sort.Strings(ret)
-	return strings.Join(ret, "\n")
+	return append(command, args...)
 }
 
-func extractAlpinePackages(parts []string) []string {
-	apk := false
-	add := false
-	var ret []string
+var buildKitPrefixRe = regexp.MustCompile(`^#\d+ (\d+\.\d+ )?`)
+
+// buildLines Split the build output into lines, removing the BuildKit step
+// and timing prefixes like `#7 3.012 `.
+func buildLines(output string) []string {
+	lines := strings.Split(output, "\n")
+
+	for i, line := range lines {
+		lines[i] = buildKitPrefixRe.ReplaceAllString(line, "")
+	}
+
+	return lines
+}
+
+// buildOptions The docker, podman and buildah build options that take a
+// value as the next argument.
+var buildOptions = map[string]bool{
+	"-t":                 true,
+	"--tag":              true,
+	"-f":                 true,
+	"--file":             true,
+	"--build-arg":        true,
+	"--build-arg-file":   true,
+	"--build-context":    true,
+	"--target":           true,
+	"--platform":         true,
+	"--label":            true,
+	"--annotation":       true,
+	"--network":          true,
+	"--secret":           true,
+	"--ssh":              true,
+	"--cache-from":       true,
+	"--cache-to":         true,
+	"-o":                 true,
+	"--output":           true,
+	"--iidfile":          true,
+	"--metadata-file":    true,
+	"--add-host":         true,
+	"--allow":            true,
+	"--attest":           true,
+	"--progress":         true,
+	"--shm-size":         true,
+	"-m":                 true,
+	"--memory":           true,
+	"--memory-swap":      true,
+	"-c":                 true,
+	"--cpu-shares":       true,
+	"--cpu-period":       true,
+	"--cpu-quota":        true,
+	"--cpuset-cpus":      true,
+	"--cpuset-mems":      true,
+	"--cgroup-parent":    true,
+	"--isolation":        true,
+	"--ulimit":           true,
+	"--security-opt":     true,
+	"--arch":             true,
+	"--os":               true,
+	"--variant":          true,
+	"--authfile":         true,
+	"--creds":            true,
+	"--cert-dir":         true,
+	"--format":           true,
+	"--jobs":             true,
+	"--manifest":         true,
+	"--runtime":          true,
+	"--timestamp":        true,
+	"--userns":           true,
+	"-v":                 true,
+	"--volume":           true,
+	"--env":              true,
+	"--from":             true,
+	"--logfile":          true,
+	"--decryption-key":   true,
+	"--signature-policy": true,
+}
 
-	for _, arg := range parts {
-		if "apk" == arg {
-			apk = true
+// buildContext Find the build context, which is the positional argument of
+// the build arguments.
+func buildContext(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case buildOptions[args[i]]:
+			i++
+		case strings.HasPrefix(args[i], "-"):
 			continue
+		default:
+			return args[i]
 		}
-		if "add" == arg {
-			add = true
-			continue
+	}
+
+	return "."
+}
+
+// dockerfilePath Find the Dockerfile of the build from the `-f` argument or
+// the build context, where podman and buildah prefer a `Containerfile`.
+func dockerfilePath(args []string) string {
+	for i, arg := range args {
+		if (arg == "-f" || arg == "--file") && i+1 < len(args) {
+			return args[i+1]
 		}
-		if apk && add {
-			ret = append(ret, arg)
+
+		if strings.HasPrefix(arg, "--file=") {
+			return strings.TrimPrefix(arg, "--file=")
 		}
 	}
+
+	context := buildContext(args)
+
+	containerfile := filepath.Join(context, "Containerfile")
+	if _, err := os.Stat(containerfile); err == nil {
+		return containerfile
+	}
+
+	return filepath.Join(context, "Dockerfile")
+}
+
+// installedAlpine Find the installed packages from the `Installing` lines of
+// the build output.
+func installedAlpine(lines []string) map[string]string {
+	ret := map[string]string{}
+
+	for _, line := range lines {
+		parts := strings.Split(line, " ")
+
+		// (3/3) Installing htop (2.2.0-r0)
+		if len(parts) < 4 || parts[1] != "Installing" {
+			continue
+		}
+
+		ret[parts[2]] = trimParens(parts[3])
+	}
+
 	return ret
 }
 
-func sanitiseAlpineVersion(version string) string {
+// extractAlpinePackages Find the packages of the `apk add` commands that do
+// not have a version constraint.
+func extractAlpinePackages(parts []string) []string {
+	line := strings.Join(parts, " ")
+
+	return packageNames(alpineWords(shellWords([]string{line}, 0, 0)))
+}
+
+// trimParens Remove the parentheses around a version of the build output,
+// like `(7.88.1-10+deb12u5)`.
+func trimParens(version string) string {
 	version = strings.TrimLeft(version, "(")
 	version = strings.TrimRight(version, ")")
 	return version
 }
 
-func bash(command string) string {
-	cmd := exec.Command("bash", "-c", command)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+var (
+	ErrorUnknownBuilder = errors.New("not a docker, podman or buildah build command")
+)
+
+// BuildOutput The writer the build output is streamed to.
+var BuildOutput io.Writer = os.Stderr
+
+// BuildError A failed build, with the build output attached.
+type BuildError struct {
+	Command []string
+	Err     error
+	Log     string
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("%s failed: %s\n%s", strings.Join(e.Command, " "), e.Err, e.Log)
+}
+
+// run Run the command without a shell, streaming both stdout and stderr to
+// out and returning them.
+func run(command []string, out io.Writer) (string, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+
+	var output bytes.Buffer
+	writer := io.MultiWriter(&output, out)
+
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
 	err := cmd.Run()
 	if err != nil {
-		panic(err)
+		return output.String(), &BuildError{
+			Command: command,
+			Err:     err,
+			Log:     output.String(),
+		}
 	}
-	outStr, _ := string(stdout.Bytes()), string(stderr.Bytes())
 
-	return outStr
+	return output.String(), nil
 }
@@ -1,6 +1,9 @@
 package docker
 
 import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -8,6 +11,32 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// buildDockerfile Recreate the Dockerfile of a build from the `Step` lines
+// of its output.
+func buildDockerfile(lines []string) string {
+	var ret string
+
+	for _, line := range lines {
+		if parts := strings.SplitN(line, " : ", 2); len(parts) == 2 && strings.HasPrefix(parts[0], "Step ") {
+			ret += parts[1] + "\n"
+		}
+	}
+
+	return ret
+}
+
+// pinInstalled Pin the packages of a Dockerfile with the installed
+// versions, like Build does.
+func pinInstalled(t *testing.T, dockerfile string, installed map[string]string, extract func([]word) []word) string {
+	anyStage := func(string) (string, error) { return "build", nil }
+	packagesOf := func(string) (map[string]string, error) { return installed, nil }
+
+	out, _, err := rewritePackages([]byte(dockerfile), anyStage, packagesOf, extract)
+	assert.Nil(t, err)
+
+	return out
+}
+
 func TestAlpine(t *testing.T) {
 	var cases = []struct {
 		name string
@@ -34,7 +63,7 @@ func TestAlpine(t *testing.T) {
 				Successfully built 7bdf00db2c0c
 				Successfully tagged foo:latest
 			`),
-			out: "htop=2.2.0-r0",
+			out: "FROM alpine\nRUN apk add htop=2.2.0-r0\n",
 		},
 		{
 			name: "single package with fixed version",
@@ -56,7 +85,7 @@ func TestAlpine(t *testing.T) {
 				Successfully built 7bdf00db2c0c
 				Successfully tagged foo:latest
 			`),
-			out: "",
+			out: "FROM alpine\nRUN apk add htop=2.2.0-r0\n",
 		},
 		{
 			name: "multiple apk packages",
@@ -176,31 +205,265 @@ func TestAlpine(t *testing.T) {
 				Removing intermediate container 565cc2239e79
 				 ---> 29469422cd39
 			`),
-			out: heredoc.Doc(`
-				build-base=0.4-r1
-				ca-certificates=20161130-r1
-				cython-dev=0.25.1-r0
-				cython=0.25.1-r0
-				eudev-dev=3.2.1-r1
-				freetype-dev=2.7-r2
-				jpeg-dev=8-r6
-				lcms2-dev=2.8-r1
-				libffi-dev=3.2.1-r2
-				libusb-dev=1.0.20-r0
-				linux-headers=4.4.6-r1
-				openjpeg-dev=2.3.0-r0
-				openssl-dev=1.0.2q-r0
-				py2-certifi=2016.9.26-r0
-				py2-lxml=3.6.4-r0
-				py2-pip=9.0.0-r1
-				tcl-dev=8.6.6-r0
-				tiff-dev=4.0.9-r6
-				tk-dev=8.6.6-r1
-				zlib-dev=1.2.11-r0`),
+			out: "FROM alpine:3.5\n" +
+				"RUN apk add --update-cache py2-pip=9.0.0-r1 ca-certificates=20161130-r1 py2-certifi=2016.9.26-r0 py2-lxml=3.6.4-r0                            python-dev cython=0.25.1-r0 cython-dev=0.25.1-r0 libusb-dev=1.0.20-r0 build-base=0.4-r1                            eudev-dev=3.2.1-r1 linux-headers=4.4.6-r1 libffi-dev=3.2.1-r2 openssl-dev=1.0.2q-r0                            jpeg-dev=8-r6 zlib-dev=1.2.11-r0 freetype-dev=2.7-r2 lcms2-dev=2.8-r1 openjpeg-dev=2.3.0-r0                            tiff-dev=4.0.9-r6 tk-dev=8.6.6-r1 tcl-dev=8.6.6-r0\n",
+		},
+	}
+
+	for _, test := range cases {
+		lines := strings.Split(test.in, "\n")
+		assert.Equal(t, test.out, pinInstalled(t, buildDockerfile(lines), installedAlpine(lines), alpineWords), test.name)
+	}
+}
+
+func TestExtractAlpinePackages(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  []string
+	}{
+		{
+			name: "command separators",
+			in:   "RUN apk add --no-cache curl && rm -rf /var/cache",
+			out:  []string{"curl"},
+		},
+		{
+			name: "apk update before apk add",
+			in:   "RUN apk update && apk add git; apk info",
+			out:  []string{"git"},
+		},
+		{
+			name: "separators without spaces",
+			in:   "RUN apk add curl&&apk add htop;echo done|tee log",
+			out:  []string{"curl", "htop"},
+		},
+		{
+			name: "options with arguments",
+			in:   "RUN apk add -t .deps --repository http://dl-cdn.alpinelinux.org/alpine/edge/testing -X https://mirror --arch=x86_64 curl",
+			out:  []string{"curl"},
+		},
+		{
+			name: "global options before add",
+			in:   "RUN /sbin/apk --no-cache add 'git' \"bash\"",
+			out:  []string{"git", "bash"},
+		},
+		{
+			name: "version constraints",
+			in:   "RUN apk add curl=8.5.0-r0 htop~3.2 git>2.40 bash<6 jq",
+			out:  []string{"jq"},
+		},
+		{
+			name: "not an apk command",
+			in:   "RUN echo apk add curl",
+			out:  nil,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, extractAlpinePackages(strings.Fields(test.in)), test.name)
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []string
+		out  []string
+	}{
+		{
+			name: "plain progress is added",
+			in:   []string{"docker", "build", "-t", "foo", "."},
+			out:  []string{"docker", "build", "--no-cache", "--progress=plain", "-t", "foo", "."},
+		},
+		{
+			name: "existing progress argument is kept",
+			in:   []string{"docker", "buildx", "build", "--progress=tty", "."},
+			out:  []string{"docker", "buildx", "build", "--no-cache", "--progress=tty", "."},
+		},
+		{
+			name: "podman does not support progress",
+			in:   []string{"/usr/bin/podman", "build", "-t", "foo", "."},
+			out:  []string{"/usr/bin/podman", "build", "--no-cache", "-t", "foo", "."},
+		},
+		{
+			name: "buildah bud",
+			in:   []string{"buildah", "bud", "."},
+			out:  []string{"buildah", "bud", "--no-cache", "."},
 		},
 	}
 
 	for _, test := range cases {
-		assert.Equal(t, test.out, alpine(strings.Split(test.in, "\n")), test.name)
+		prefix, rest, ok := splitBuild(test.in)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.out, buildCommand(prefix, rest), test.name)
+	}
+}
+
+func TestIsBuild(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []string
+		out  bool
+	}{
+		{name: "docker build", in: []string{"docker", "build", "."}, out: true},
+		{name: "docker image build", in: []string{"docker", "image", "build", "."}, out: true},
+		{name: "podman build", in: []string{"podman", "build", "."}, out: true},
+		{name: "buildah build-using-dockerfile", in: []string{"buildah", "build-using-dockerfile", "."}, out: true},
+		{name: "docker run", in: []string{"docker", "run", "alpine"}, out: false},
+		{name: "buildah without arguments", in: []string{"buildah", "bud"}, out: false},
+		{name: "file", in: []string{"Dockerfile"}, out: false},
 	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, IsBuild(test.in), test.name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var streamed bytes.Buffer
+
+	output, err := run([]string{"sh", "-c", "echo out; echo 'quoted arg' >&2"}, &streamed)
+	assert.Nil(t, err)
+	assert.Equal(t, "out\nquoted arg\n", output)
+	assert.Equal(t, output, streamed.String())
+
+	_, err = run([]string{"sh", "-c", "echo failing >&2; exit 3"}, &streamed)
+	buildErr, ok := err.(*BuildError)
+	assert.True(t, ok)
+	assert.Equal(t, "failing\n", buildErr.Log)
+	assert.Contains(t, err.Error(), "exit status 3")
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	docker := filepath.Join(dir, "docker")
+
+	// a fake docker that prints BuildKit output to stderr
+	script := heredoc.Doc(`
+		#!/bin/sh
+		cat >&2 <<EOF
+		#5 [2/2] RUN apk add --no-cache htop
+		#5 0.214 fetch https://dl-cdn.alpinelinux.org/alpine/v3.19/main/x86_64/APKINDEX.tar.gz
+		#5 0.630 (1/2) Installing ncurses-libs (6.4_p20231125-r0)
+		#5 0.640 (2/2) Installing htop (3.2.2-r1)
+		#5 DONE 0.7s
+		EOF
+	`)
+
+	err := ioutil.WriteFile(docker, []byte(script), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dockerfile := filepath.Join(dir, "Dockerfile")
+
+	err = ioutil.WriteFile(dockerfile, []byte("FROM alpine:3.19\nRUN apk add --no-cache htop\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := BuildOutput
+	defer func() { BuildOutput = output }()
+
+	BuildOutput = ioutil.Discard
+
+	err = Build([]string{docker, "build", "-t", "foo", dir})
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM alpine:3.19\nRUN apk add --no-cache htop=3.2.2-r1\n", string(slurp(t, dockerfile)))
+
+	err = Build([]string{filepath.Join(dir, "missing"), "build", dir})
+	assert.NotNil(t, err)
+}
+
+func TestBuildMultiStage(t *testing.T) {
+	dir := t.TempDir()
+	docker := filepath.Join(dir, "docker")
+
+	// a fake docker where an alpine and a debian stage install curl
+	script := heredoc.Doc(`
+		#!/bin/sh
+		cat >&2 <<EOF
+		#5 [build 2/2] RUN apk add curl
+		#5 0.630 (1/1) Installing curl (8.5.0-r0)
+		#5 DONE 0.7s
+		#8 [final 2/2] RUN apt-get install -y curl
+		#8 1.630 Setting up curl (7.88.1-10+deb12u5) ...
+		#8 DONE 1.7s
+		EOF
+	`)
+
+	err := ioutil.WriteFile(docker, []byte(script), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dockerfile := filepath.Join(dir, "Dockerfile")
+
+	err = ioutil.WriteFile(dockerfile, []byte(heredoc.Doc(`
+		FROM alpine:3.19 AS build
+		RUN apk add curl
+		FROM debian:bookworm AS final
+		RUN apt-get install -y curl
+	`)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := BuildOutput
+	defer func() { BuildOutput = output }()
+
+	BuildOutput = ioutil.Discard
+
+	err = Build([]string{docker, "build", dir})
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		FROM alpine:3.19 AS build
+		RUN apk add curl=8.5.0-r0
+		FROM debian:bookworm AS final
+		RUN apt-get install -y curl=7.88.1-10+deb12u5
+	`), string(slurp(t, dockerfile)))
+}
+
+func TestBuildPodman(t *testing.T) {
+	dir := t.TempDir()
+	podman := filepath.Join(dir, "podman")
+
+	// a fake podman that prints buildah output and fails on --progress
+	script := heredoc.Doc(`
+		#!/bin/sh
+		for arg in "$@"; do
+			[ "$arg" = "--progress=plain" ] && exit 125
+		done
+		cat <<EOF
+		STEP 1/2: FROM debian:bookworm
+		STEP 2/2: RUN apt-get update && apt-get install -y curl
+		Get:1 http://deb.debian.org/debian bookworm InRelease [151 kB]
+		Setting up curl (7.88.1-10+deb12u5) ...
+		COMMIT foo
+		--> 3f7c87692ab9
+		EOF
+	`)
+
+	err := ioutil.WriteFile(podman, []byte(script), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	containerfile := filepath.Join(dir, "Containerfile")
+
+	err = ioutil.WriteFile(containerfile, []byte("FROM debian:bookworm\nRUN apt-get update && apt-get install -y curl\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := BuildOutput
+	defer func() { BuildOutput = output }()
+
+	BuildOutput = ioutil.Discard
+
+	err = Build([]string{podman, "build", "-t", "foo", dir})
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM debian:bookworm\nRUN apt-get update && apt-get install -y curl=7.88.1-10+deb12u5\n", string(slurp(t, containerfile)))
+
+	assert.Equal(t, ErrorUnknownBuilder, Build([]string{"kaniko", "build", dir}))
 }
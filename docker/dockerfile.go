@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoFromInstruction = errors.New("no FROM instruction found")
+)
+
+// From A `FROM [--platform=<platform>] <image> [AS <name>]` instruction.
+type From struct {
+	Line     int
+	Platform string
+	Image    string
+	Stage    string
+}
+
+var fromRe = regexp.MustCompile(`(?i)^\s*FROM\s+`)
+
+// ParseFrom Find all the FROM instructions of a Dockerfile.
+func ParseFrom(lines []string) []From {
+	var ret []From
+
+	for i, line := range lines {
+		if !fromRe.MatchString(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)[1:]
+		from := From{Line: i}
+
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			if strings.HasPrefix(fields[0], "--platform=") {
+				from.Platform = strings.TrimPrefix(fields[0], "--platform=")
+			}
+
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		from.Image = fields[0]
+
+		if len(fields) == 3 && strings.EqualFold(fields[1], "as") {
+			from.Stage = fields[2]
+		}
+
+		ret = append(ret, from)
+	}
+
+	return ret
+}
+
+// UpdateDockerfile Update the image tags of the FROM instructions to the
+// newest tag with the same shape.
+func UpdateDockerfile(bytesIn []byte, registry *Registry) (string, error) {
+	return rewriteDockerfile(bytesIn, func(image string) string {
+		return updateImage(image, registry)
+	})
+}
+
+// rewriteDockerfile Replace the images of the FROM instructions, skipping
+// references to earlier build stages, `scratch` and images that use build
+// arguments.
+func rewriteDockerfile(bytesIn []byte, update func(string) string) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+
+	froms := ParseFrom(lines)
+	if len(froms) == 0 {
+		return "", ErrorNoFromInstruction
+	}
+
+	stages := map[string]bool{}
+
+	for _, from := range froms {
+		image := from.Image
+
+		if stages[strings.ToLower(image)] || image == "scratch" || strings.Contains(image, "$") {
+			log.WithFields(log.Fields{
+				"image": image,
+			}).Debug("Skipping FROM instruction")
+		} else {
+			lines[from.Line] = replaceImage(lines[from.Line], image, update(image))
+		}
+
+		if from.Stage != "" {
+			stages[strings.ToLower(from.Stage)] = true
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// replaceImage Replace the image in the line, making sure that a tag like
+// `node:20` does not match `node:20-alpine`.
+func replaceImage(line, old, new string) string {
+	re := regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(old) + `(\s|$)`)
+
+	return re.ReplaceAllString(line, "${1}"+strings.ReplaceAll(new, "$", "$$")+"${2}")
+}
+
+// updateImage Return the image with the newest tag of the same shape.
+func updateImage(image string, registry *Registry) string {
+	ref, err := ParseReference(image)
+	if err != nil || ref.Tag == "" || ref.Digest != "" {
+		return image
+	}
+
+	tags, err := registry.Tags(ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"image": image,
+		}).Error("Cannot list image tags")
+
+		return image
+	}
+
+	ref.Tag = NewestTag(ref.Tag, tags)
+
+	log.WithFields(log.Fields{
+		"image": image,
+		"new":   ref.String(),
+	}).Debug("Newest image tag")
+
+	return ref.String()
+}
+
+// IsDockerfile Check if a file name looks like a Dockerfile, for example
+// `Dockerfile`, `Dockerfile.dev` or `app.Dockerfile`.
+func IsDockerfile(file string) bool {
+	name := strings.ToLower(filepath.Base(file))
+
+	return name == "dockerfile" ||
+		strings.HasPrefix(name, "dockerfile.") ||
+		strings.HasSuffix(name, ".dockerfile")
+}
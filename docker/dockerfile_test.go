@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDockerfile(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "multi-stage build with platform",
+			in: heredoc.Doc(`
+				FROM --platform=$BUILDPLATFORM node:18-alpine AS build
+				RUN npm ci
+				FROM build as test
+				FROM alpine:3.17
+				COPY --from=build /app /app
+			`),
+			out: heredoc.Doc(`
+				FROM --platform=$BUILDPLATFORM node:21-alpine AS build
+				RUN npm ci
+				FROM build as test
+				FROM alpine:3.19
+				COPY --from=build /app /app
+			`),
+		},
+		{
+			name: "images that cannot be updated",
+			in: heredoc.Doc(`
+				ARG BASE=alpine
+				FROM ${BASE}:3.17
+				from alpine
+				FROM scratch
+				FROM node:latest
+			`),
+			out: heredoc.Doc(`
+				ARG BASE=alpine
+				FROM ${BASE}:3.17
+				from alpine
+				FROM scratch
+				FROM node:latest
+			`),
+		},
+		{
+			name: "not a Dockerfile",
+			in:   "foo: bar",
+			err:  ErrorNoFromInstruction,
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	for _, test := range cases {
+		out, err := UpdateDockerfile([]byte(test.in), &registry)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestParseFrom(t *testing.T) {
+	lines := []string{
+		"FROM --platform=linux/amd64 golang:1.21 AS builder",
+		"RUN go build",
+		"FROM builder",
+	}
+
+	assert.Equal(t, []From{
+		{Line: 0, Platform: "linux/amd64", Image: "golang:1.21", Stage: "builder"},
+		{Line: 2, Image: "builder"},
+	}, ParseFrom(lines))
+}
+
+func TestIsDockerfile(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  bool
+	}{
+		{name: "plain Dockerfile", in: "docker/Dockerfile", out: true},
+		{name: "Dockerfile with suffix", in: "Dockerfile.dev", out: true},
+		{name: "Dockerfile with prefix", in: "app.dockerfile", out: true},
+		{name: "other file", in: "docker-compose.yml", out: false},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, IsDockerfile(test.in), test.name)
+	}
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dpkgVersion A debian package version, `[epoch:]upstream[-revision]`.
+type dpkgVersion struct {
+	epoch    int
+	upstream string
+	revision string
+}
+
+// parseDpkgVersion Split a debian package version into its epoch, upstream
+// version and revision.
+func parseDpkgVersion(version string) dpkgVersion {
+	var ret dpkgVersion
+
+	if pos := strings.Index(version, ":"); pos >= 0 {
+		ret.epoch, _ = strconv.Atoi(version[0:pos])
+		version = version[pos+1:]
+	}
+
+	ret.upstream = version
+	if pos := strings.LastIndex(version, "-"); pos >= 0 {
+		ret.upstream, ret.revision = version[0:pos], version[pos+1:]
+	}
+
+	return ret
+}
+
+// dpkgOrder The sort weight of a character of a non digit part, where `~`
+// sorts before everything, even the end of the part, and letters sort
+// before the other characters.
+func dpkgOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c >= '0' && c <= '9':
+		return 0
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return int(c)
+	}
+
+	return int(c) + 256
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// dpkgCompareString Compare an upstream version or a revision the way dpkg
+// does, alternating between non digit parts, compared character by
+// character, and digit parts, compared as numbers.
+func dpkgCompareString(a, b string) int {
+	for a != "" || b != "" {
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			var ac, bc int
+			if a != "" && !isDigit(a[0]) {
+				ac = dpkgOrder(a[0])
+				a = a[1:]
+			}
+
+			if b != "" && !isDigit(b[0]) {
+				bc = dpkgOrder(b[0])
+				b = b[1:]
+			}
+
+			if ac != bc {
+				return ac - bc
+			}
+		}
+
+		for a != "" && a[0] == '0' {
+			a = a[1:]
+		}
+
+		for b != "" && b[0] == '0' {
+			b = b[1:]
+		}
+
+		diff := 0
+		for a != "" && isDigit(a[0]) && b != "" && isDigit(b[0]) {
+			if diff == 0 {
+				diff = int(a[0]) - int(b[0])
+			}
+
+			a, b = a[1:], b[1:]
+		}
+
+		if a != "" && isDigit(a[0]) {
+			return 1
+		}
+
+		if b != "" && isDigit(b[0]) {
+			return -1
+		}
+
+		if diff != 0 {
+			return diff
+		}
+	}
+
+	return 0
+}
+
+// dpkgLess Compare two debian package versions by their epoch, upstream
+// version and revision.
+func dpkgLess(a, b string) bool {
+	va, vb := parseDpkgVersion(a), parseDpkgVersion(b)
+
+	if va.epoch != vb.epoch {
+		return va.epoch < vb.epoch
+	}
+
+	if diff := dpkgCompareString(va.upstream, vb.upstream); diff != 0 {
+		return diff < 0
+	}
+
+	return dpkgCompareString(va.revision, vb.revision) < 0
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDpkgLess(t *testing.T) {
+	var cases = []struct {
+		name string
+		a    string
+		b    string
+		out  bool
+	}{
+		{
+			name: "epoch wins over the upstream version",
+			a:    "2.0-1",
+			b:    "1:9.2p1-2+deb12u2",
+			out:  true,
+		},
+		{
+			name: "epoch is not less than no epoch",
+			a:    "1:9.2p1-2+deb12u2",
+			b:    "2.0-1",
+			out:  false,
+		},
+		{
+			name: "tilde sorts before the end of the version",
+			a:    "2.9.14+dfsg-1.3~deb12u1",
+			b:    "2.9.14+dfsg-1.3",
+			out:  true,
+		},
+		{
+			name: "release is not less than its tilde backport",
+			a:    "2.9.14+dfsg-1.3",
+			b:    "2.9.14+dfsg-1.3~deb12u1",
+			out:  false,
+		},
+		{
+			name: "newer security update",
+			a:    "9.2p1-2+deb12u2",
+			b:    "9.2p1-2+deb12u3",
+			out:  true,
+		},
+		{
+			name: "numbers are compared as numbers",
+			a:    "1.9-1",
+			b:    "1.10-1",
+			out:  true,
+		},
+		{
+			name: "letters sort before other characters",
+			a:    "1.0a",
+			b:    "1.0+",
+			out:  true,
+		},
+		{
+			name: "revision breaks ties",
+			a:    "7.88.1-10",
+			b:    "7.88.1-10+deb12u5",
+			out:  true,
+		},
+		{
+			name: "upstream version with hyphens",
+			a:    "1.2-rc1-1",
+			b:    "1.2-rc2-1",
+			out:  true,
+		},
+		{
+			name: "equal versions",
+			a:    "1:2.3-4",
+			b:    "1:2.3-4",
+			out:  false,
+		},
+		{
+			name: "leading zeros",
+			a:    "1.01",
+			b:    "1.1",
+			out:  false,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, dpkgLess(test.a, test.b), test.name)
+	}
+}
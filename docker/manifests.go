@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/mhristof/zoi/yamlnode"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrorNoImages = errors.New("no `image:` fields found")
+)
+
+// yamlValue A scalar of a YAML file and its position, so that it can be
+// replaced without touching the comments and the layout of the file.
+type yamlValue struct {
+	node *yaml.Node
+	// kustomize is set for the `newTag` fields of kustomize `images:`
+	// blocks, and holds the image the tag belongs to.
+	kustomize string
+}
+
+// yamlImages Find the `image:` fields of all the documents of a YAML file,
+// like docker-compose files and Kubernetes manifests, and the `newTag`
+// fields of kustomize `images:` blocks.
+func yamlImages(bytesIn []byte) ([]yamlValue, error) {
+	var ret []yamlValue
+
+	decoder := yaml.NewDecoder(bytes.NewReader(bytesIn))
+
+	for {
+		var document yaml.Node
+
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse yaml")
+		}
+
+		walkImages(&document, &ret)
+
+		if len(document.Content) > 0 {
+			ret = append(ret, kustomizeImages(document.Content[0])...)
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, ErrorNoImages
+	}
+
+	return ret, nil
+}
+
+func walkImages(node *yaml.Node, values *[]yamlValue) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			if key.Value == "image" && value.Kind == yaml.ScalarNode && value.Value != "" {
+				*values = append(*values, yamlValue{node: value})
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		walkImages(child, values)
+	}
+}
+
+// kustomizeImages Find the `newTag` fields of the top level `images:` block
+// of a kustomization file.
+func kustomizeImages(root *yaml.Node) []yamlValue {
+	var ret []yamlValue
+
+	images := yamlnode.MappingValue(root, "images")
+	if images == nil || images.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, image := range images.Content {
+		name := yamlnode.MappingValue(image, "name")
+		tag := yamlnode.MappingValue(image, "newTag")
+
+		if name == nil || tag == nil || tag.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		if newName := yamlnode.MappingValue(image, "newName"); newName != nil {
+			name = newName
+		}
+
+		ret = append(ret, yamlValue{node: tag, kustomize: name.Value})
+	}
+
+	return ret
+}
+
+// replaceYAML Replace the values in place, keeping the quotes, the
+// comments and the layout of the file.
+func replaceYAML(bytesIn []byte, values []yamlValue, update func(yamlValue) string) string {
+	updates := map[*yaml.Node]string{}
+
+	for _, value := range values {
+		updates[value.node] = update(value)
+	}
+
+	return yamlnode.Replace(bytesIn, updates)
+}
+
+// UpdateImages Update the image tags of docker-compose files, Kubernetes
+// manifests and kustomize `images:` blocks to the newest tag of the same
+// shape.
+func UpdateImages(bytesIn []byte, registry *Registry) (string, error) {
+	values, err := yamlImages(bytesIn)
+	if err != nil {
+		return "", err
+	}
+
+	return replaceYAML(bytesIn, values, func(value yamlValue) string {
+		if value.kustomize == "" {
+			if strings.Contains(value.node.Value, "$") {
+				return value.node.Value
+			}
+
+			return updateImage(value.node.Value, registry)
+		}
+
+		ref, err := ParseReference(value.kustomize + ":" + value.node.Value)
+		if err != nil {
+			return value.node.Value
+		}
+
+		updated, err := ParseReference(updateImage(ref.String(), registry))
+		if err != nil {
+			return value.node.Value
+		}
+
+		return updated.Tag
+	}), nil
+}
+
+// PinImages Pin the `image:` fields of YAML files like docker-compose files
+// and Kubernetes manifests.
+func PinImages(bytesIn []byte, registry *Registry) (string, error) {
+	values, err := yamlImages(bytesIn)
+	if err != nil {
+		return "", err
+	}
+
+	return replaceYAML(bytesIn, values, func(value yamlValue) string {
+		if value.kustomize != "" || strings.Contains(value.node.Value, "$") {
+			return value.node.Value
+		}
+
+		return Pin(value.node.Value, registry)
+	}), nil
+}
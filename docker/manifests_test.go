@@ -0,0 +1,189 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateImages(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "docker-compose file",
+			in: heredoc.Doc(`
+				# the web services
+				services:
+				  web:
+				    image: "node:18-alpine" # frontend
+				  db:
+				    image: ${DB_IMAGE}
+				  cache:
+				    image: alpine:latest
+			`),
+			out: heredoc.Doc(`
+				# the web services
+				services:
+				  web:
+				    image: "node:21-alpine" # frontend
+				  db:
+				    image: ${DB_IMAGE}
+				  cache:
+				    image: alpine:latest
+			`),
+		},
+		{
+			name: "multi-document kubernetes manifests with init containers",
+			in: heredoc.Doc(`
+				apiVersion: apps/v1
+				kind: Deployment
+				spec:
+				  template:
+				    spec:
+				      initContainers:
+				        - name: init
+				          image: alpine:3.17
+				      containers:
+				        - {name: app, image: 'owner/tool:v1.0.0'}
+				---
+				apiVersion: batch/v1
+				kind: CronJob
+				spec:
+				  jobTemplate:
+				    spec:
+				      template:
+				        spec:
+				          containers:
+				            - image: alpine:3.18 # pinned by ops
+			`),
+			out: heredoc.Doc(`
+				apiVersion: apps/v1
+				kind: Deployment
+				spec:
+				  template:
+				    spec:
+				      initContainers:
+				        - name: init
+				          image: alpine:3.19
+				      containers:
+				        - {name: app, image: 'owner/tool:v1.10.0'}
+				---
+				apiVersion: batch/v1
+				kind: CronJob
+				spec:
+				  jobTemplate:
+				    spec:
+				      template:
+				        spec:
+				          containers:
+				            - image: alpine:3.19 # pinned by ops
+			`),
+		},
+		{
+			name: "kustomize images block",
+			in: heredoc.Doc(`
+				resources:
+				  - deployment.yaml
+				images:
+				  - name: alpine
+				    newTag: "3.17"
+				  - name: app
+				    newName: owner/tool
+				    newTag: v1.2.0 # app version
+				  - name: digest-only
+				    digest: sha256:abcd
+			`),
+			out: heredoc.Doc(`
+				resources:
+				  - deployment.yaml
+				images:
+				  - name: alpine
+				    newTag: "3.19"
+				  - name: app
+				    newName: owner/tool
+				    newTag: v1.10.0 # app version
+				  - name: digest-only
+				    digest: sha256:abcd
+			`),
+		},
+		{
+			name: "yaml without images",
+			in:   "foo: bar\n",
+			err:  ErrorNoImages,
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	for _, test := range cases {
+		out, err := UpdateImages([]byte(test.in), &registry)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestPinImages(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "docker-compose file",
+			in: heredoc.Doc(`
+				services:
+				  web:
+				    image: "node:20-alpine" # frontend
+				  db:
+				    image: ${DB_IMAGE}
+			`),
+			out: heredoc.Doc(`
+				services:
+				  web:
+				    image: "node:20.11.1-alpine@sha256:2011" # frontend
+				  db:
+				    image: ${DB_IMAGE}
+			`),
+		},
+		{
+			name: "kubernetes manifest",
+			in: heredoc.Doc(`
+				spec:
+				  containers:
+				    - image: alpine:3.19.1
+				      name: app
+			`),
+			out: heredoc.Doc(`
+				spec:
+				  containers:
+				    - image: alpine:3.19.1@sha256:library-alpine-3.19.1
+				      name: app
+			`),
+		},
+		{
+			name: "yaml without images",
+			in:   "foo: bar",
+			err:  ErrorNoImages,
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	for _, test := range cases {
+		out, err := PinImages([]byte(test.in), &registry)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
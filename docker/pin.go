@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/mhristof/zoi/log"
+)
+
+var versionRe = regexp.MustCompile(`N(\.N)*`)
+
+// refines Check if the tag is a more specific version of the current tag,
+// like `20.11.1-alpine` is for `20-alpine`.
+func refines(current, tag string) bool {
+	currentShape := tagShape(current)
+	shape := tagShape(tag)
+
+	if currentShape == current || shape == currentShape {
+		return false
+	}
+
+	if versionRe.ReplaceAllString(currentShape, "V") != versionRe.ReplaceAllString(shape, "V") {
+		return false
+	}
+
+	currentNumbers := tagNumbers(current)
+	numbers := tagNumbers(tag)
+
+	if len(numbers) <= len(currentNumbers) {
+		return false
+	}
+
+	for i := range currentNumbers {
+		if currentNumbers[i] != numbers[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pin Pin an image to the digest of its tag. Floating tags like
+// `20-alpine` are replaced with the most specific tag that points to the
+// same digest, like `20.11.1-alpine`. Existing digests are refreshed.
+func Pin(image string, registry *Registry) string {
+	ref, err := ParseReference(image)
+	if err != nil || ref.Tag == "" {
+		return image
+	}
+
+	digest, err := registry.Digest(ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"image": image,
+		}).Error("Cannot resolve image digest")
+
+		return image
+	}
+
+	ref.Digest = digest
+
+	tags, err := registry.Tags(ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"image": image,
+		}).Debug("Cannot list image tags")
+
+		return ref.String()
+	}
+
+	var candidates []string
+	for _, tag := range tags {
+		if refines(ref.Tag, tag) {
+			candidates = append(candidates, tag)
+		}
+	}
+
+	// most specific and newest tags first
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(tagNumbers(candidates[i])) != len(tagNumbers(candidates[j])) {
+			return len(tagNumbers(candidates[i])) > len(tagNumbers(candidates[j]))
+		}
+
+		return tagLess(candidates[j], candidates[i])
+	})
+
+	for _, tag := range candidates {
+		candidate := *ref
+		candidate.Tag = tag
+
+		candidateDigest, err := registry.Digest(&candidate)
+		if err != nil || candidateDigest != digest {
+			continue
+		}
+
+		ref.Tag = tag
+
+		break
+	}
+
+	log.WithFields(log.Fields{
+		"image":  image,
+		"pinned": ref.String(),
+	}).Debug("Pinned image")
+
+	return ref.String()
+}
+
+// PinDockerfile Pin the images of the FROM instructions to their digests.
+func PinDockerfile(bytesIn []byte, registry *Registry) (string, error) {
+	return rewriteDockerfile(bytesIn, func(image string) string {
+		return Pin(image, registry)
+	})
+}
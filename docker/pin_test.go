@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPin(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "floating tag",
+			in:   "node:20-alpine",
+			out:  "node:20.11.1-alpine@sha256:2011",
+		},
+		{
+			name: "outdated digest",
+			in:   "node:20.11.1-alpine@sha256:old",
+			out:  "node:20.11.1-alpine@sha256:2011",
+		},
+		{
+			name: "tag without more specific tags",
+			in:   "alpine:3.19.1",
+			out:  "alpine:3.19.1@sha256:library-alpine-3.19.1",
+		},
+		{
+			name: "image without a tag",
+			in:   "alpine",
+			out:  "alpine",
+		},
+		{
+			name: "unknown tag",
+			in:   "alpine:missing",
+			out:  "alpine:missing",
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, Pin(test.in, &registry), test.name)
+	}
+}
+
+func TestPinDockerfile(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	out, err := PinDockerfile([]byte(heredoc.Doc(`
+		FROM node:20-alpine AS build
+		FROM build
+	`)), &registry)
+
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		FROM node:20.11.1-alpine@sha256:2011 AS build
+		FROM build
+	`), out)
+}
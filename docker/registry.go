@@ -0,0 +1,315 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultDomain   = "docker.io"
+	defaultRegistry = "registry-1.docker.io"
+)
+
+var (
+	ErrorEmptyReference  = errors.New("empty image reference")
+	ErrorUnauthorized    = errors.New("registry authentication failed")
+	ErrorWrongAuthHeader = errors.New("cannot parse the WWW-Authenticate header")
+	ErrorNoDigest        = errors.New("registry did not return a digest")
+)
+
+// Reference An image reference like `ghcr.io/owner/image:tag@sha256:...`.
+type Reference struct {
+	// Name is the image name as it was written, for example `node` for
+	// `docker.io/library/node`.
+	Name   string
+	Domain string
+	Path   string
+	Tag    string
+	Digest string
+}
+
+// ParseReference Parse an image reference, applying the Docker Hub defaults
+// for the domain and the `library/` namespace.
+func ParseReference(in string) (*Reference, error) {
+	if in == "" {
+		return nil, ErrorEmptyReference
+	}
+
+	ret := Reference{}
+	name := in
+
+	if pos := strings.Index(name, "@"); pos >= 0 {
+		ret.Digest = name[pos+1:]
+		name = name[0:pos]
+	}
+
+	if pos := strings.LastIndex(name, ":"); pos >= 0 && !strings.Contains(name[pos:], "/") {
+		ret.Tag = name[pos+1:]
+		name = name[0:pos]
+	}
+
+	ret.Name = name
+	ret.Domain = DefaultDomain
+	ret.Path = name
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ret.Domain = parts[0]
+		ret.Path = parts[1]
+	}
+
+	if ret.Domain == DefaultDomain && !strings.Contains(ret.Path, "/") {
+		ret.Path = "library/" + ret.Path
+	}
+
+	return &ret, nil
+}
+
+// String Return the reference in the same form as it was parsed.
+func (r *Reference) String() string {
+	ret := r.Name
+
+	if r.Tag != "" {
+		ret += ":" + r.Tag
+	}
+
+	if r.Digest != "" {
+		ret += "@" + r.Digest
+	}
+
+	return ret
+}
+
+// Registry A client for the OCI distribution API.
+type Registry struct {
+	// URL overrides the registry of every image, for example to use a
+	// local registry.
+	URL    string
+	Client *http.Client
+	tokens map[string]string
+}
+
+func (r *Registry) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+
+	return r.Client
+}
+
+func (r *Registry) base(ref *Reference) string {
+	if r.URL != "" {
+		return strings.TrimSuffix(r.URL, "/")
+	}
+
+	if ref.Domain == DefaultDomain {
+		return "https://" + defaultRegistry
+	}
+
+	return "https://" + ref.Domain
+}
+
+var challengeRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// token Retrieve an anonymous bearer token following the
+// `WWW-Authenticate` challenge of the registry.
+func (r *Registry) token(challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", ErrorWrongAuthHeader
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeRe.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm, ok := params["realm"]
+	if !ok {
+		return "", ErrorWrongAuthHeader
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+
+	resp, err := r.client().Get(realm + "?" + query.Encode())
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get registry token")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", ErrorUnauthorized
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot decode registry token")
+	}
+
+	if body.Token == "" {
+		return body.AccessToken, nil
+	}
+
+	return body.Token, nil
+}
+
+// do Run a request against the registry, authenticating once if the
+// registry asks for it.
+func (r *Registry) do(method, address string, headers map[string]string) (*http.Response, error) {
+	if r.tokens == nil {
+		r.tokens = map[string]string{}
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens are scoped to a repository, so share them between the tags and
+	// manifests endpoints.
+	key := u.Host + u.Path
+	for _, endpoint := range []string{"/tags/", "/manifests/"} {
+		if pos := strings.LastIndex(key, endpoint); pos > 0 {
+			key = key[0:pos]
+		}
+	}
+
+	for retry := 0; retry < 2; retry++ {
+		req, err := http.NewRequest(method, address, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		if token, ok := r.tokens[key]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := r.client().Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot %s %s", method, address)
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized || retry > 0 {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		token, err := r.token(resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return nil, err
+		}
+
+		r.tokens[key] = token
+	}
+
+	return nil, ErrorUnauthorized
+}
+
+var linkRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Tags List all the tags of an image, following the pagination links.
+func (r *Registry) Tags(ref *Reference) ([]string, error) {
+	var ret []string
+
+	next := fmt.Sprintf("%s/v2/%s/tags/list", r.base(ref), ref.Path)
+
+	for next != "" {
+		resp, err := r.do(http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read tags")
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cannot list tags of %s: %s", ref.Name, resp.Status)
+		}
+
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+
+		err = json.Unmarshal(body, &list)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode tags")
+		}
+
+		ret = append(ret, list.Tags...)
+		next = ""
+
+		if match := linkRe.FindStringSubmatch(resp.Header.Get("Link")); len(match) > 0 {
+			link, err := resp.Request.URL.Parse(match[1])
+			if err != nil {
+				return nil, err
+			}
+
+			next = link.String()
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"image": ref.Name,
+		"tags":  len(ret),
+	}).Debug("Image tags")
+
+	return ret, nil
+}
+
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Digest Resolve the digest of an image tag with a HEAD request. Multi
+// platform images resolve to the digest of their manifest list.
+func (r *Registry) Digest(ref *Reference) (string, error) {
+	address := fmt.Sprintf("%s/v2/%s/manifests/%s", r.base(ref), ref.Path, ref.Tag)
+
+	resp, err := r.do(http.MethodHead, address, map[string]string{
+		"Accept": strings.Join(manifestTypes, ", "),
+	})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot resolve %s:%s: %s", ref.Name, ref.Tag, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", ErrorNoDigest
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,182 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testImages = map[string][]string{
+	"library/alpine": {"3.17", "3.18", "3.18.4", "3.19", "3.19.1", "edge", "latest"},
+	"library/node":   {"18-alpine", "20", "20-alpine", "20.11-alpine", "20.11.0-alpine", "20.11.1-alpine", "21-alpine", "21.5.0-alpine", "22-alpine3.19"},
+	"owner/tool":     {"v1.0.0", "v1.2.0", "v1.10.0", "v2.0.0-rc1"},
+}
+
+// testDigests Tags that share a manifest, every other tag has a unique
+// digest.
+var testDigests = map[string]string{
+	"library/node:20-alpine":      "sha256:2011",
+	"library/node:20.11-alpine":   "sha256:2011",
+	"library/node:20.11.1-alpine": "sha256:2011",
+	"library/node:20.11.0-alpine": "sha256:2010",
+}
+
+func testDigest(name, tag string) string {
+	if digest, ok := testDigests[name+":"+tag]; ok {
+		return digest
+	}
+
+	return fmt.Sprintf("sha256:%s-%s", strings.ReplaceAll(name, "/", "-"), tag)
+}
+
+// testRegistry A local stand-in of a registry that requires an anonymous
+// bearer token and paginates the tag lists by 3.
+func testRegistry(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "secret"}`)
+	})
+
+	for name, tags := range testImages {
+		name, tags := name, tags
+
+		mux.HandleFunc(fmt.Sprintf("/v2/%s/tags/list", name), func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="registry.test",scope="repository:%s:pull"`, server.URL, name,
+				))
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			page := tags
+			if r.URL.Query().Get("last") == "" && len(tags) > 3 {
+				page = tags[0:3]
+				w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=3&last=%s>; rel="next"`, name, tags[2]))
+			} else if r.URL.Query().Get("last") != "" {
+				page = tags[3:]
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"name": name,
+				"tags": page,
+			})
+		})
+
+		mux.HandleFunc(fmt.Sprintf("/v2/%s/manifests/", name), func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead || !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json") {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			tag := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/v2/%s/manifests/", name))
+			for _, known := range tags {
+				if known == tag {
+					w.Header().Set("Docker-Content-Digest", testDigest(name, tag))
+
+					return
+				}
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+		})
+	}
+
+	return server
+}
+
+func TestParseReference(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  *Reference
+		err  error
+	}{
+		{
+			name: "official image",
+			in:   "alpine:3.18",
+			out: &Reference{
+				Name:   "alpine",
+				Domain: "docker.io",
+				Path:   "library/alpine",
+				Tag:    "3.18",
+			},
+		},
+		{
+			name: "registry with port and digest",
+			in:   "localhost:5000/owner/tool:v1@sha256:abcd",
+			out: &Reference{
+				Name:   "localhost:5000/owner/tool",
+				Domain: "localhost:5000",
+				Path:   "owner/tool",
+				Tag:    "v1",
+				Digest: "sha256:abcd",
+			},
+		},
+		{
+			name: "ghcr image without tag",
+			in:   "ghcr.io/owner/tool",
+			out: &Reference{
+				Name:   "ghcr.io/owner/tool",
+				Domain: "ghcr.io",
+				Path:   "owner/tool",
+			},
+		},
+		{
+			name: "empty reference",
+			in:   "",
+			err:  ErrorEmptyReference,
+		},
+	}
+
+	for _, test := range cases {
+		ref, err := ParseReference(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, ref, test.name)
+
+		if err == nil {
+			assert.Equal(t, test.in, ref.String(), test.name)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	ref, _ := ParseReference("alpine:3.18")
+	tags, err := registry.Tags(ref)
+	assert.Nil(t, err)
+	assert.Equal(t, testImages["library/alpine"], tags)
+
+	ref, _ = ParseReference("missing:1.0")
+	_, err = registry.Tags(ref)
+	assert.NotNil(t, err)
+}
+
+func TestDigest(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	ref, _ := ParseReference("node:20-alpine")
+	digest, err := registry.Digest(ref)
+	assert.Nil(t, err)
+	assert.Equal(t, "sha256:2011", digest)
+
+	ref, _ = ParseReference("node:missing")
+	_, err = registry.Digest(ref)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,331 @@
+package docker
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+)
+
+// word A whitespace separated word of a Dockerfile instruction and its
+// position, so that it can be replaced without touching the layout of the
+// instruction.
+type word struct {
+	line  int
+	start int
+	end   int
+	text  string
+}
+
+// shellWords Split the lines of an instruction into shell words, dropping
+// the `\\` line continuations and the quotes. Command separators like `&&`
+// and `;` are returned as separate words even if they are not surrounded by
+// spaces.
+func shellWords(lines []string, first, last int) []word {
+	var ret []word
+
+	for i := first; i <= last; i++ {
+		line := lines[i]
+		pos := 0
+
+		for pos < len(line) {
+			c := line[pos]
+
+			switch {
+			case c == ' ' || c == '\t':
+				pos++
+			case c == '\\' && strings.TrimSpace(line[pos+1:]) == "":
+				// line continuation
+				pos = len(line)
+			case c == '#':
+				// comment until the end of the line
+				pos = len(line)
+			case strings.IndexByte(separators, c) >= 0:
+				end := pos + 1
+				if end < len(line) && line[end] == c && c != ';' {
+					end++
+				}
+
+				ret = append(ret, word{line: i, start: pos, end: end, text: line[pos:end]})
+				pos = end
+			default:
+				end, text := scanWord(line, pos)
+				ret = append(ret, word{line: i, start: pos, end: end, text: text})
+				pos = end
+			}
+		}
+	}
+
+	return ret
+}
+
+const separators = ";&|"
+
+// scanWord Scan a shell word starting at pos, removing its quotes and
+// escapes. Returns the end of the word and its value.
+func scanWord(line string, pos int) (int, string) {
+	var text strings.Builder
+
+	for pos < len(line) {
+		c := line[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || strings.IndexByte(separators, c) >= 0:
+			return pos, text.String()
+		case c == '\\' && strings.TrimSpace(line[pos+1:]) == "":
+			return pos, text.String()
+		case c == '\\':
+			if pos+1 < len(line) {
+				text.WriteByte(line[pos+1])
+			}
+
+			pos += 2
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(line[pos+1:], c)
+			if end < 0 {
+				text.WriteString(line[pos+1:])
+
+				return len(line), text.String()
+			}
+
+			text.WriteString(line[pos+1 : pos+1+end])
+			pos += end + 2
+		default:
+			text.WriteByte(c)
+			pos++
+		}
+	}
+
+	return pos, text.String()
+}
+
+// instructionEnd Find the last line of the instruction starting at the line.
+func instructionEnd(lines []string, first int) int {
+	last := first
+
+	for last < len(lines)-1 && strings.HasSuffix(strings.TrimRight(lines[last], " \t"), "\\") {
+		last++
+	}
+
+	return last
+}
+
+// packageName Return the package name of a word like `curl=8.5.0-r0`.
+func packageName(word string) string {
+	if pos := strings.IndexAny(word, "=~<>"); pos > 0 {
+		return word[0:pos]
+	}
+
+	return word
+}
+
+// isSeparator Check if the word ends a command.
+func isSeparator(w word) bool {
+	return w.text != "" && strings.Trim(w.text, separators) == ""
+}
+
+// alpineOptions The `apk` options that take an argument.
+var alpineOptions = map[string]bool{
+	"-t":                  true,
+	"--virtual":           true,
+	"-X":                  true,
+	"--repository":        true,
+	"-p":                  true,
+	"--root":              true,
+	"--arch":              true,
+	"--cache-dir":         true,
+	"--keys-dir":          true,
+	"--repositories-file": true,
+	"--progress-fd":       true,
+	"--timeout":           true,
+}
+
+// alpineWords Find the package words of the `apk add` commands, skipping
+// the options and their arguments.
+func alpineWords(words []word) []word {
+	return commandWords(words, "apk", []string{"add"}, alpineOptions)
+}
+
+// commandPrefixes Words that can come before the command name.
+var commandPrefixes = map[string]bool{
+	"RUN":  true,
+	"sudo": true,
+	"exec": true,
+	"then": true,
+	"do":   true,
+}
+
+// commandWords Find the package words of a package manager command, for
+// example `apk add`.
+func commandWords(words []word, command string, subcommands []string, options map[string]bool) []word {
+	var ret []word
+
+	// start is set while looking for the command name, which has to be the
+	// first word of a command, after prefixes like `RUN` or `sudo`.
+	start := true
+	found := false
+	subcommand := false
+	skipArgument := false
+
+	for _, w := range words {
+		switch {
+		case isSeparator(w):
+			start, found, subcommand, skipArgument = true, false, false, false
+		case !found && (commandPrefixes[w.text] || (start && strings.Contains(w.text, "="))):
+			start = true
+		case start:
+			start = false
+			found = path.Base(w.text) == command
+		case !found:
+			continue
+		case skipArgument:
+			skipArgument = false
+		case options[w.text]:
+			skipArgument = true
+		case strings.HasPrefix(w.text, "-"):
+			continue
+		case !subcommand:
+			subcommand = contains(subcommands, w.text)
+			found = subcommand
+		default:
+			ret = append(ret, w)
+		}
+	}
+
+	return ret
+}
+
+func contains(list []string, item string) bool {
+	for _, value := range list {
+		if value == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rewritePackages Replace the packages of the `RUN` instructions with their
+// pinned versions, including packages that are already pinned.
+func rewritePackages(
+	bytesIn []byte,
+	suiteOf func(string) (string, error),
+	packagesOf func(string) (map[string]string, error),
+	extract func([]word) []word,
+) (string, bool, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	tracker := newStageTracker(suiteOf)
+
+	for first := 0; first < len(lines); first++ {
+		last := instructionEnd(lines, first)
+
+		if tracker.from(lines[first]) || tracker.suite == "" {
+			first = last
+
+			continue
+		}
+
+		words := extract(shellWords(lines, first, last))
+		if len(words) > 0 {
+			packages, err := packagesOf(tracker.suite)
+			if err != nil {
+				return "", tracker.found, err
+			}
+
+			for i := len(words) - 1; i >= 0; i-- {
+				w := words[i]
+				name := packageName(w.text)
+
+				version, ok := packages[name]
+				if !ok {
+					log.WithFields(log.Fields{
+						"package": name,
+						"suite":   tracker.suite,
+					}).Debug("Package not found, leaving it as is")
+
+					continue
+				}
+
+				lines[w.line] = lines[w.line][0:w.start] + name + "=" + version + lines[w.line][w.end:]
+			}
+		}
+
+		first = last
+	}
+
+	return strings.Join(lines, "\n"), tracker.found, nil
+}
+
+// RewriteAlpine Pin the `apk add` packages of a Dockerfile to the latest
+// versions of the APKINDEX of the alpine branch of every stage.
+func RewriteAlpine(bytesIn []byte, index *APKIndex) (string, error) {
+	ret, found, err := rewritePackages(bytesIn, AlpineBranch, index.Packages, alpineWords)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", ErrorNoAlpineStage
+	}
+
+	return ret, nil
+}
+
+// aptOptions The `apt-get` options that take an argument.
+var aptOptions = map[string]bool{
+	"-o":               true,
+	"--option":         true,
+	"-t":               true,
+	"--target-release": true,
+	"-c":               true,
+	"--config-file":    true,
+}
+
+// aptWords Find the package words of the `apt-get install` commands.
+func aptWords(words []word) []word {
+	ret := append(
+		commandWords(words, "apt-get", []string{"install"}, aptOptions),
+		commandWords(words, "apt", []string{"install"}, aptOptions)...,
+	)
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].line != ret[j].line {
+			return ret[i].line < ret[j].line
+		}
+
+		return ret[i].start < ret[j].start
+	})
+
+	return ret
+}
+
+// packageNames Return the names of the packages without a version
+// constraint, like `curl` but not `git=2.43.0-r0` or `htop>3`.
+func packageNames(words []word) []string {
+	var ret []string
+
+	for _, w := range words {
+		if packageName(w.text) == w.text {
+			ret = append(ret, w.text)
+		}
+	}
+
+	return ret
+}
+
+// RewriteDebian Pin the `apt-get install` packages of a Dockerfile to the
+// latest versions of the Packages index of the debian or ubuntu release of
+// every stage.
+func RewriteDebian(bytesIn []byte, index *DebianIndex) (string, error) {
+	ret, found, err := rewritePackages(bytesIn, DebianSuite, index.Packages, aptWords)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", ErrorNoDebianStage
+	}
+
+	return ret, nil
+}
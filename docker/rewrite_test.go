@@ -0,0 +1,147 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteAlpine(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "multi-line instruction with flags",
+			in: heredoc.Doc(`
+				FROM alpine:3.19
+				RUN apk add --no-cache --virtual .build-deps \
+				        curl \
+				        git=2.40.0-r0 \
+				    && apk add htop missing\
+				    && rm -rf /var/cache/apk
+			`),
+			out: heredoc.Doc(`
+				FROM alpine:3.19
+				RUN apk add --no-cache --virtual .build-deps \
+				        curl=8.5.0-r0 \
+				        git=2.43.0-r10 \
+				    && apk add htop=3.2.2-r1 missing\
+				    && rm -rf /var/cache/apk
+			`),
+		},
+		{
+			name: "non alpine stages are left untouched",
+			in: heredoc.Doc(`
+				FROM golang:1.21-alpine AS build
+				RUN apk add git
+				FROM alpine:3.19
+				RUN apk add git
+			`),
+			out: heredoc.Doc(`
+				FROM golang:1.21-alpine AS build
+				RUN apk add git
+				FROM alpine:3.19
+				RUN apk add git=2.43.0-r10
+			`),
+		},
+		{
+			name: "stage that inherits an alpine stage",
+			in: heredoc.Doc(`
+				FROM golang:1.21 AS build
+				RUN apk add curl
+				FROM alpine:3.19 AS base
+				FROM base
+				RUN apk add htop missing
+			`),
+			out: heredoc.Doc(`
+				FROM golang:1.21 AS build
+				RUN apk add curl
+				FROM alpine:3.19 AS base
+				FROM base
+				RUN apk add htop=3.2.2-r1 missing
+			`),
+		},
+		{
+			name: "no alpine stage",
+			in:   "FROM debian:12\n",
+			err:  ErrorNoAlpineStage,
+		},
+	}
+
+	index := APKIndex{Files: []string{"../test/fixtures/APKINDEX"}}
+
+	for _, test := range cases {
+		out, err := RewriteAlpine([]byte(test.in), &index)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestRewriteDebian(t *testing.T) {
+	in := heredoc.Doc(`
+		FROM debian:bookworm
+		RUN apt-get update \
+		 && apt-get install -y -o Dpkg::Options::=--force-confnew \
+		    curl=7.88.1-10+deb12u4 ca-certificates \
+		 && rm -rf /var/lib/apt/lists/*
+	`)
+	out := heredoc.Doc(`
+		FROM debian:bookworm
+		RUN apt-get update \
+		 && apt-get install -y -o Dpkg::Options::=--force-confnew \
+		    curl=7.88.1-10+deb12u5 ca-certificates=20230311 \
+		 && rm -rf /var/lib/apt/lists/*
+	`)
+
+	index := DebianIndex{Files: []string{"../test/fixtures/Packages"}}
+
+	rewritten, err := RewriteDebian([]byte(in), &index)
+	assert.Nil(t, err)
+	assert.Equal(t, out, rewritten)
+
+	_, err = RewriteDebian([]byte("FROM alpine:3.19\nRUN apk add curl\n"), &index)
+	assert.Equal(t, ErrorNoDebianStage, err)
+}
+
+func TestDockerfilePath(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []string
+		out  string
+	}{
+		{name: "build context", in: []string{"-t", "foo", "."}, out: "Dockerfile"},
+		{name: "file argument", in: []string{"-f", "docker/Dockerfile.dev", "."}, out: "docker/Dockerfile.dev"},
+		{name: "file argument with =", in: []string{"--file=app.Dockerfile", "ctx"}, out: "app.Dockerfile"},
+		{name: "context directory", in: []string{"-t", "foo", "docker"}, out: "docker/Dockerfile"},
+		{name: "options after the context", in: []string{"docker", "-t", "foo"}, out: "docker/Dockerfile"},
+		{name: "build arg last", in: []string{"--no-cache", "docker", "--build-arg", "X=y"}, out: "docker/Dockerfile"},
+		{name: "options with =", in: []string{"--tag=foo", "--platform=linux/amd64", "docker"}, out: "docker/Dockerfile"},
+		{name: "no context", in: []string{"-t", "foo"}, out: "Dockerfile"},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, dockerfilePath(test.in), test.name)
+	}
+}
+
+func TestShellWords(t *testing.T) {
+	lines := []string{
+		`RUN apk add "curl" \`,
+		`    git&&echo 'a b' # comment`,
+	}
+
+	assert.Equal(t, []word{
+		{line: 0, start: 0, end: 3, text: "RUN"},
+		{line: 0, start: 4, end: 7, text: "apk"},
+		{line: 0, start: 8, end: 11, text: "add"},
+		{line: 0, start: 12, end: 18, text: "curl"},
+		{line: 1, start: 4, end: 7, text: "git"},
+		{line: 1, start: 7, end: 9, text: "&&"},
+		{line: 1, start: 9, end: 13, text: "echo"},
+		{line: 1, start: 14, end: 19, text: "a b"},
+	}, shellWords(lines, 0, 1))
+}
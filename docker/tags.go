@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var numbersRe = regexp.MustCompile(`\d+`)
+
+// tagShape Return the tag with all the numbers replaced, so that tags like
+// `3.18-alpine` and `3.19-alpine` have the same shape.
+func tagShape(tag string) string {
+	return numbersRe.ReplaceAllString(tag, "N")
+}
+
+func tagNumbers(tag string) []int {
+	var ret []int
+
+	for _, number := range numbersRe.FindAllString(tag, -1) {
+		value, err := strconv.Atoi(number)
+		if err != nil {
+			value = 0
+		}
+
+		ret = append(ret, value)
+	}
+
+	return ret
+}
+
+// tagLess Compare two tags of the same shape number by number.
+func tagLess(a, b string) bool {
+	aNumbers := tagNumbers(a)
+	bNumbers := tagNumbers(b)
+
+	for i := range aNumbers {
+		if i >= len(bNumbers) {
+			return false
+		}
+
+		if aNumbers[i] != bNumbers[i] {
+			return aNumbers[i] < bNumbers[i]
+		}
+	}
+
+	return len(aNumbers) < len(bNumbers)
+}
+
+// sameScale Check that the numbers of a tag have at most one digit more
+// than the numbers of the current tag, so that `9` can become `10` but a
+// date stamped build like `20231012` is not picked instead of `20`.
+func sameScale(current, tag string) bool {
+	currentNumbers := numbersRe.FindAllString(current, -1)
+
+	for i, number := range numbersRe.FindAllString(tag, -1) {
+		if i < len(currentNumbers) && len(number) > len(currentNumbers[i])+1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewestTag Find the newest tag that has the same shape and scale as the
+// current one. Tags without any numbers, like `latest`, are returned as they
+// are.
+func NewestTag(current string, tags []string) string {
+	shape := tagShape(current)
+	if shape == current {
+		return current
+	}
+
+	newest := current
+
+	for _, tag := range tags {
+		if tagShape(tag) != shape || !sameScale(current, tag) {
+			continue
+		}
+
+		if tagLess(newest, tag) {
+			newest = tag
+		}
+	}
+
+	return newest
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewestTag(t *testing.T) {
+	var cases = []struct {
+		name    string
+		current string
+		tags    []string
+		out     string
+	}{
+		{
+			name:    "same shape with a suffix",
+			current: "18-alpine",
+			tags:    testImages["library/node"],
+			out:     "21-alpine",
+		},
+		{
+			name:    "major.minor only",
+			current: "3.17",
+			tags:    testImages["library/alpine"],
+			out:     "3.19",
+		},
+		{
+			name:    "numeric comparison instead of lexical",
+			current: "v1.0.0",
+			tags:    testImages["owner/tool"],
+			out:     "v1.10.0",
+		},
+		{
+			name:    "tag without a version",
+			current: "latest",
+			tags:    testImages["library/alpine"],
+			out:     "latest",
+		},
+		{
+			name:    "date stamped builds",
+			current: "20-alpine",
+			tags:    []string{"21-alpine", "20231012-alpine"},
+			out:     "21-alpine",
+		},
+		{
+			name:    "one more digit",
+			current: "9.6",
+			tags:    []string{"9.6", "10.1", "20231012.1"},
+			out:     "10.1",
+		},
+		{
+			name:    "current tag is the newest",
+			current: "3.19.1",
+			tags:    []string{"3.18.4"},
+			out:     "3.19.1",
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, NewestTag(test.current, test.tags), test.name)
+	}
+}
@@ -0,0 +1,144 @@
+package gh
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mhristof/zoi/log"
+)
+
+// minAssetScore The similarity an asset name needs to have with the
+// expected one to be picked instead of it.
+const minAssetScore = 0.6
+
+var (
+	assetRe      = regexp.MustCompile(`/releases/download/([^/]+)/([^/?#]+)`)
+	assetSplitRe = regexp.MustCompile(`[-_.]+`)
+	numberRe     = regexp.MustCompile(`^v?\d+$`)
+)
+
+// platforms Aliases of the same platform used in asset names.
+var platforms = strings.NewReplacer(
+	"x86_64", "amd64",
+	"x86-64", "amd64",
+	"aarch64", "arm64",
+	"macos", "darwin",
+	"osx", "darwin",
+)
+
+// assetTokens Split an asset name into its lowercase words, without the
+// version numbers.
+func assetTokens(name, release string) map[string]bool {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, strings.TrimPrefix(strings.ToLower(release), "v"), "")
+	name = platforms.Replace(name)
+
+	ret := map[string]bool{}
+
+	for _, token := range assetSplitRe.Split(name, -1) {
+		if token == "" || numberRe.MatchString(token) {
+			continue
+		}
+
+		ret[token] = true
+	}
+
+	return ret
+}
+
+// similarity The Jaccard index of the words of two asset names.
+func similarity(a, b map[string]bool) float64 {
+	common := 0
+
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+
+	total := len(a) + len(b) - common
+	if total == 0 {
+		return 0
+	}
+
+	return float64(common) / float64(total)
+}
+
+// bestAsset Find the asset of the release whose name is the most similar
+// to the expected one. Ties and assets that are not similar enough are not
+// picked, as the wrong platform would be downloaded.
+func bestAsset(expected, release string, assets []string) (string, bool) {
+	wanted := assetTokens(expected, release)
+	best := ""
+	bestScore := 0.0
+	tie := false
+
+	for _, asset := range assets {
+		score := similarity(wanted, assetTokens(asset, release))
+
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = asset, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+
+	if tie || bestScore < minAssetScore {
+		return "", false
+	}
+
+	return best, true
+}
+
+// verifyAsset Check that the asset of a release download URL exists in the
+// release. Renamed assets are replaced with the most similar asset of the
+// release and, if there is none, the current URL is kept.
+func (u *Url) verifyAsset(client *github.Client, next string) string {
+	found := assetRe.FindStringSubmatch(next)
+	if found == nil || strings.ContainsAny(found[2], "$%{}") {
+		return next
+	}
+
+	tag, asset := found[1], found[2]
+
+	release, _, err := client.Repositories.GetReleaseByTag(context.Background(), u.Owner, u.Repo, tag)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"tag": tag,
+		}).Debug("Cannot list the release assets")
+
+		return next
+	}
+
+	var assets []string
+	for _, releaseAsset := range release.Assets {
+		if releaseAsset.GetName() == asset {
+			return next
+		}
+
+		assets = append(assets, releaseAsset.GetName())
+	}
+
+	best, ok := bestAsset(asset, tag, assets)
+	if !ok {
+		log.WithFields(log.Fields{
+			"asset": asset,
+			"tag":   tag,
+			"u.Url": u.Url,
+		}).Warning("Release asset not found, keeping the current version")
+
+		return u.Url
+	}
+
+	log.WithFields(log.Fields{
+		"asset": asset,
+		"best":  best,
+		"tag":   tag,
+	}).Warning("Release asset not found, using the most similar asset")
+
+	return strings.Replace(next, "/"+asset, "/"+best, 1)
+}
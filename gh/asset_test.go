@@ -0,0 +1,113 @@
+package gh
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestAsset(t *testing.T) {
+	var cases = []struct {
+		name     string
+		expected string
+		assets   []string
+		out      string
+		ok       bool
+	}{
+		{
+			name:     "renamed asset",
+			expected: "tool_1.3.0_linux_amd64.tar.gz",
+			assets: []string{
+				"tool-darwin-amd64.tar.gz",
+				"tool-linux-amd64.tar.gz",
+				"tool-linux-arm64.tar.gz",
+				"checksums.txt",
+			},
+			out: "tool-linux-amd64.tar.gz",
+			ok:  true,
+		},
+		{
+			name:     "platform aliases",
+			expected: "tool_1.3.0_Linux_x86_64.tar.gz",
+			assets:   []string{"tool-v1.3.0-linux-amd64.tar.gz", "tool-v1.3.0-darwin-amd64.tar.gz"},
+			out:      "tool-v1.3.0-linux-amd64.tar.gz",
+			ok:       true,
+		},
+		{
+			name:     "ambiguous assets",
+			expected: "tool_1.3.0_linux.tar.gz",
+			assets:   []string{"tool-linux-amd64.tar.gz", "tool-linux-arm64.tar.gz"},
+		},
+		{
+			name:     "no similar asset",
+			expected: "tool_1.3.0_linux_amd64.tar.gz",
+			assets:   []string{"source.zip", "checksums.txt"},
+		},
+	}
+
+	for _, test := range cases {
+		out, ok := bestAsset(test.expected, "v1.3.0", test.assets)
+		assert.Equal(t, test.ok, ok, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestVerifyAsset(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/repos/owner/tool/releases/tags/v1.3.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.3.0", "assets": [
+			{"name": "tool_1.3.0_linux_amd64.tar.gz"},
+			{"name": "tool_1.3.0_darwin_amd64.tar.gz"}
+		]}`)
+	})
+	mux.HandleFunc("/repos/owner/tool/releases/tags/v1.4.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.4.0", "assets": [
+			{"name": "tool-linux-amd64.tar.gz"},
+			{"name": "tool-darwin-amd64.tar.gz"},
+			{"name": "checksums.txt"}
+		]}`)
+	})
+	mux.HandleFunc("/repos/owner/tool/releases/tags/v1.5.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v1.5.0", "assets": [{"name": "source.zip"}]}`)
+	})
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	current := "https://github.com/owner/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz"
+	u := Url{Owner: "owner", Repo: "tool", Release: "v1.2.0", Url: current}
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "asset exists",
+			in:   "https://github.com/owner/tool/releases/download/v1.3.0/tool_1.3.0_linux_amd64.tar.gz",
+			out:  "https://github.com/owner/tool/releases/download/v1.3.0/tool_1.3.0_linux_amd64.tar.gz",
+		},
+		{
+			name: "renamed asset",
+			in:   "https://github.com/owner/tool/releases/download/v1.4.0/tool_1.4.0_linux_amd64.tar.gz",
+			out:  "https://github.com/owner/tool/releases/download/v1.4.0/tool-linux-amd64.tar.gz",
+		},
+		{
+			name: "no similar asset",
+			in:   "https://github.com/owner/tool/releases/download/v1.5.0/tool_1.5.0_linux_amd64.tar.gz",
+			out:  current,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, u.verifyAsset(client, test.in), test.name)
+	}
+}
@@ -0,0 +1,119 @@
+package gh
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mhristof/zoi/log"
+)
+
+// TagLister List the tags of repositories, like Client does.
+type TagLister interface {
+	Tags(owner, repo string) ([]string, error)
+}
+
+// Repositories Find the tags and compare the commits of repositories, like
+// Client does.
+type Repositories interface {
+	LatestTag(owner, repo string) (string, string, error)
+	Ahead(owner, repo, base, head string) (bool, error)
+}
+
+// NextCommit Find the latest tag of a repository and the commit it points
+// to, if that commit is ahead of the current one. The current commit can be
+// abbreviated.
+func NextCommit(repos Repositories, owner, repo, current string) (string, string, bool) {
+	tag, sha, err := repos.LatestTag(owner, repo)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":  err,
+			"repo": owner + "/" + repo,
+		}).Error("Cannot find the latest tag")
+
+		return "", "", false
+	}
+
+	if strings.HasPrefix(sha, current) {
+		return "", "", false
+	}
+
+	ahead, err := repos.Ahead(owner, repo, current, sha)
+	if err != nil || !ahead {
+		log.WithFields(log.Fields{
+			"err":    err,
+			"commit": current,
+			"tag":    tag,
+		}).Debug("Latest tag is not ahead of the commit")
+
+		return "", "", false
+	}
+
+	return tag, sha, true
+}
+
+// Client A GitHub API client for the updaters that need more than the next
+// release of a URL.
+type Client struct {
+	Token string
+}
+
+// LatestTag Find the latest tag of a repository, same as NextRelease does
+// with tags, and the commit it points to.
+func (c *Client) LatestTag(owner, repo string) (string, string, error) {
+	tag, err := latestTagRef(newClient(c.Token), owner, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	return tag.GetName(), tag.GetCommit().GetSHA(), nil
+}
+
+// Ahead Check if the head commit is ahead of the base commit.
+func (c *Client) Ahead(owner, repo, base, head string) (bool, error) {
+	comparison, _, err := newClient(c.Token).Repositories.CompareCommits(context.Background(), owner, repo, base, head)
+	if err != nil {
+		return false, err
+	}
+
+	return comparison.GetStatus() == "ahead", nil
+}
+
+// Commit Find the commit a ref like a branch or `HEAD` points to, and its
+// commit date.
+func (c *Client) Commit(owner, repo, ref string) (string, time.Time, error) {
+	commit, _, err := newClient(c.Token).Repositories.GetCommit(context.Background(), owner, repo, ref)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return commit.GetSHA(), commit.GetCommit().GetCommitter().GetDate(), nil
+}
+
+// Tags List the names of all the tags of a repository.
+func (c *Client) Tags(owner, repo string) ([]string, error) {
+	client := newClient(c.Token)
+	opt := &github.ListOptions{PerPage: 100}
+
+	var ret []string
+
+	for {
+		tags, resp, err := client.Repositories.ListTags(context.Background(), owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			ret = append(ret, tag.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return ret, nil
+}
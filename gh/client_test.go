@@ -0,0 +1,89 @@
+package gh
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRepos map[string]string
+
+func (r testRepos) LatestTag(owner, repo string) (string, string, error) {
+	sha, ok := r[owner+"/"+repo]
+	if !ok {
+		return "", "", errors.New("not found")
+	}
+
+	return "v2.0.0", sha, nil
+}
+
+func (r testRepos) Ahead(owner, repo, base, head string) (bool, error) {
+	return base != "fedcba9876543210fedcba9876543210fedcba98", nil
+}
+
+func TestNextCommit(t *testing.T) {
+	repos := testRepos{
+		"owner/tool": "0123456789abcdef0123456789abcdef01234567",
+	}
+
+	var cases = []struct {
+		name    string
+		repo    string
+		current string
+		tag     string
+		sha     string
+		moved   bool
+	}{
+		{
+			name:    "tag ahead of the commit",
+			repo:    "tool",
+			current: "1111111111111111111111111111111111111111",
+			tag:     "v2.0.0",
+			sha:     "0123456789abcdef0123456789abcdef01234567",
+			moved:   true,
+		},
+		{
+			name:    "abbreviated commit of the tag",
+			repo:    "tool",
+			current: "0123456",
+		},
+		{
+			name:    "commit ahead of the tag",
+			repo:    "tool",
+			current: "fedcba9876543210fedcba9876543210fedcba98",
+		},
+		{
+			name:    "unknown repository",
+			repo:    "missing",
+			current: "1111111111111111111111111111111111111111",
+		},
+	}
+
+	for _, test := range cases {
+		tag, sha, moved := NextCommit(repos, "owner", test.repo, test.current)
+		assert.Equal(t, test.moved, moved, test.name)
+		assert.Equal(t, test.tag, tag, test.name)
+		assert.Equal(t, test.sha, sha, test.name)
+	}
+}
+
+func TestMissingRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	_, err := latestTagRef(client, "owner", "typo")
+	assert.NotNil(t, err)
+
+	_, err = latestRelease(client, "owner", "typo")
+	assert.NotNil(t, err)
+}
@@ -25,6 +25,18 @@ func Release(line string, prefTags bool, token string) string {
 			}).Debug("Wrong parser")
 			continue
 		}
+
+		if strings.Contains(gURL.Release, "$") {
+			// the release comes from a variable, which variables.Update
+			// bumps at its definition
+			log.WithFields(log.Fields{
+				"release": gURL.Release,
+				"line":    line,
+			}).Debug("Release is a variable")
+
+			return line
+		}
+
 		gURL.Token = token
 
 		next, err := gURL.NextRelease(prefTags)
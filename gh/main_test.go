@@ -57,3 +57,24 @@ func TestRelease(t *testing.T) {
 		assert.Equal(t, test.out, Release(test.in, false, ghToken), test.name)
 	}
 }
+
+func TestReleaseVariable(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "shell variable",
+			in:   "curl -sL https://github.com/o/r/releases/download/v${TOOL_VERSION}/tool_${TOOL_VERSION}_linux_amd64.tar.gz",
+		},
+		{
+			name: "make variable",
+			in:   "curl -sL https://github.com/o/r/releases/download/v$(TOOL_VERSION)/tool_linux_amd64.tar.gz",
+		},
+	}
+
+	// no token, as the GitHub API is not called for variables
+	for _, test := range cases {
+		assert.Equal(t, test.in, Release(test.in, false, ""), test.name)
+	}
+}
@@ -0,0 +1,81 @@
+package gh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+)
+
+// Resolver Find the next release of a GitHub URL. Updaters of other file
+// types take a Resolver instead of calling NextRelease directly, so that
+// they can be tested without the GitHub API.
+type Resolver func(u *Url) (string, error)
+
+// NewResolver Return a Resolver that calls NextRelease with the token.
+func NewResolver(prefTags bool, token string) Resolver {
+	return func(u *Url) (string, error) {
+		u.Token = token
+
+		return u.NextRelease(prefTags)
+	}
+}
+
+// StaticResolver Return a Resolver with a fixed next release per
+// `owner/repo`, which replaces the release in the URL like NextRelease does,
+// for example to test the updaters without the GitHub API.
+func StaticResolver(releases map[string]string) Resolver {
+	return func(u *Url) (string, error) {
+		release, ok := releases[u.Owner+"/"+u.Repo]
+		if !ok {
+			return "", fmt.Errorf("unknown repository %s/%s", u.Owner, u.Repo)
+		}
+
+		if u.Release == "" {
+			return release, nil
+		}
+
+		return strings.NewReplacer(
+			u.Release, release,
+			strings.TrimPrefix(u.Release, "v"), strings.TrimPrefix(release, "v"),
+		).Replace(u.Url), nil
+	}
+}
+
+// RepoRelease Return the URL of a release of a GitHub repository, with the
+// release as the Url so that NextRelease returns the next release itself.
+func RepoRelease(owner, repo, release string) *Url {
+	return &Url{
+		Host:    "https://github.com",
+		Owner:   owner,
+		Repo:    repo,
+		Release: release,
+		Url:     release,
+	}
+}
+
+// Next Return the next release of a GitHub repository when the ref is a
+// version, and the ref as it is otherwise, for example for commits.
+func (r Resolver) Next(owner, repo, ref string) string {
+	if _, err := versions.Parse(ref); err != nil {
+		log.WithFields(log.Fields{
+			"ref": ref,
+		}).Debug("Git ref is not a version")
+
+		return ref
+	}
+
+	next, err := r(RepoRelease(owner, repo, ref))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"owner": owner,
+			"repo":  repo,
+		}).Error("Cannot find the next release")
+
+		return ref
+	}
+
+	return next
+}
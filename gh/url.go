@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/coreos/go-semver/semver"
@@ -28,6 +29,11 @@ var (
 	ErrorCannotHandleURL  = errors.New("cannot handle the url")
 	ErrorNoTags           = errors.New("no tags available")
 	ErrorReleaseNotInTags = errors.New("release string not a tag")
+	ErrorNotReleaseURL    = errors.New("not a GitHub archive or release download URL")
+)
+
+var releaseURLRe = regexp.MustCompile(
+	`^https://github\.com/([^/]+)/([^/]+)/(archive/(refs/tags/)?(.+?)\.(tar\.gz|zip)|releases/download/([^/]+)/)`,
 )
 
 func ParseGitUrl(url string) (*Url, error) {
@@ -96,6 +102,24 @@ func ParseHttpUrl(url string) (*Url, error) {
 	}, nil
 }
 
+// ParseReleaseUrl Parse a GitHub archive or release download URL, like
+// `.../archive/refs/tags/v1.2.3.tar.gz` or
+// `.../releases/download/v1.2.3/tool.tar.gz`, with the tag as the release.
+func ParseReleaseUrl(url string) (*Url, error) {
+	found := releaseURLRe.FindStringSubmatch(url)
+	if found == nil {
+		return nil, ErrorNotReleaseURL
+	}
+
+	return &Url{
+		Host:    "https://github.com",
+		Owner:   found[1],
+		Repo:    found[2],
+		Release: found[5] + found[7],
+		Url:     url,
+	}, nil
+}
+
 func sanitiseRepo(repo string) string {
 	refPos := strings.Index(repo, "?ref")
 	if refPos > 0 {
@@ -126,13 +150,7 @@ func (u *Url) NextRelease(prefTags bool) (string, error) {
 		}).Panic("url.Token not set")
 	}
 
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: u.Token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-
-	client := github.NewClient(tc)
+	client := newClient(u.Token)
 
 	tag, tagErr := latestTag(client, u.Owner, u.Repo)
 	release, releaseErr := latestRelease(client, u.Owner, u.Repo)
@@ -150,6 +168,12 @@ func (u *Url) NextRelease(prefTags bool) (string, error) {
 	}
 
 	if releaseErr != nil && tagErr != nil {
+		log.WithFields(log.Fields{
+			"tagErr":     tagErr,
+			"releaseErr": releaseErr,
+			"u.Url":      u.Url,
+		}).Debug("Cannot find the latest tag or release")
+
 		return "", ErrorCannotHandleURL
 	}
 
@@ -159,22 +183,39 @@ func (u *Url) NextRelease(prefTags bool) (string, error) {
 		"release":   release,
 	}).Debug("New release")
 
-	return u.sanitize(release), nil
+	return u.verifyAsset(client, u.sanitize(release)), nil
+}
+
+func newClient(token string) *github.Client {
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+
+	return github.NewClient(oauth2.NewClient(context.Background(), ts))
 }
 
 func latestTag(client *github.Client, owner, repo string) (string, error) {
+	tag, err := latestTagRef(client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	return tag.GetName(), nil
+}
+
+// latestTagRef Find the latest tag that is not a pre-release, along with
+// the commit it points to.
+func latestTagRef(client *github.Client, owner, repo string) (*github.RepositoryTag, error) {
 	ctx := context.Background()
 	opt := &github.ListOptions{}
 
 	tags, _, err := client.Repositories.ListTags(ctx, owner, repo, opt)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"err": err,
-		}).Panic("Could not retrieve information from server")
+		return nil, fmt.Errorf("cannot list the tags of %s/%s: %w", owner, repo, err)
 	}
 
 	if len(tags) == 0 {
-		return "", ErrorNoTags
+		return nil, ErrorNoTags
 	}
 
 	latest := tags[0]
@@ -197,7 +238,7 @@ func latestTag(client *github.Client, owner, repo string) (string, error) {
 		"*latest.Name": *latest.Name,
 	}).Debug("Latest release name")
 
-	return *latest.Name, nil
+	return latest, nil
 }
 
 func latestRelease(client *github.Client, owner, repo string) (string, error) {
@@ -206,9 +247,7 @@ func latestRelease(client *github.Client, owner, repo string) (string, error) {
 
 	releases, _, err := client.Repositories.ListReleases(ctx, owner, repo, opt)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"err": err,
-		}).Panic("Could not retrieve information from server")
+		return "", fmt.Errorf("cannot list the releases of %s/%s: %w", owner, repo, err)
 	}
 
 	if len(releases) == 0 {
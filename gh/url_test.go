@@ -80,6 +80,60 @@ func TestParseHttpUrl(t *testing.T) {
 	}
 }
 
+func TestParseReleaseUrl(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  *Url
+		err  error
+	}{
+		{
+			name: "release download",
+			in:   "https://github.com/owner/tool/releases/download/v1.2.3/tool_1.2.3_linux.tar.gz",
+			out: &Url{
+				Host:    "https://github.com",
+				Owner:   "owner",
+				Repo:    "tool",
+				Release: "v1.2.3",
+				Url:     "https://github.com/owner/tool/releases/download/v1.2.3/tool_1.2.3_linux.tar.gz",
+			},
+		},
+		{
+			name: "tag archive",
+			in:   "https://github.com/owner/tool/archive/refs/tags/v1.2.3.tar.gz",
+			out: &Url{
+				Host:    "https://github.com",
+				Owner:   "owner",
+				Repo:    "tool",
+				Release: "v1.2.3",
+				Url:     "https://github.com/owner/tool/archive/refs/tags/v1.2.3.tar.gz",
+			},
+		},
+		{
+			name: "short archive with a tag prefix",
+			in:   "https://github.com/owner/tool/archive/kustomize/v5.0.0.zip",
+			out: &Url{
+				Host:    "https://github.com",
+				Owner:   "owner",
+				Repo:    "tool",
+				Release: "kustomize/v5.0.0",
+				Url:     "https://github.com/owner/tool/archive/kustomize/v5.0.0.zip",
+			},
+		},
+		{
+			name: "repository url",
+			in:   "https://github.com/owner/tool",
+			err:  ErrorNotReleaseURL,
+		},
+	}
+
+	for _, test := range cases {
+		url, err := ParseReleaseUrl(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, url, test.name)
+	}
+}
+
 func TestParseGitUrl(t *testing.T) {
 	var cases = []struct {
 		name string
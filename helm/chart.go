@@ -0,0 +1,139 @@
+package helm
+
+import (
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/mhristof/zoi/yamlnode"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ChartFile = "Chart.yaml"
+	LockFile  = "Chart.lock"
+)
+
+var (
+	ErrorNoDependencies = errors.New("no `dependencies:` found")
+)
+
+// Dependency A dependency of `Chart.yaml` or `Chart.lock`. The fields and
+// their order match the ones of Helm, so that the digest of `Chart.lock`
+// is the same.
+type Dependency struct {
+	Name         string        `json:"name" yaml:"name"`
+	Version      string        `json:"version,omitempty" yaml:"version"`
+	Repository   string        `json:"repository" yaml:"repository"`
+	Condition    string        `json:"condition,omitempty" yaml:"condition"`
+	Tags         []string      `json:"tags,omitempty" yaml:"tags"`
+	Enabled      bool          `json:"enabled,omitempty" yaml:"enabled"`
+	ImportValues []interface{} `json:"import-values,omitempty" yaml:"import-values"`
+	Alias        string        `json:"alias,omitempty" yaml:"alias"`
+}
+
+// parse Parse a YAML file and return its top level mapping.
+func parse(bytesIn []byte) (*yaml.Node, error) {
+	var document yaml.Node
+
+	err := yaml.Unmarshal(bytesIn, &document)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse yaml")
+	}
+
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("yaml is not a mapping")
+	}
+
+	return document.Content[0], nil
+}
+
+// Dependencies Parse the `dependencies:` of a `Chart.yaml` or `Chart.lock`.
+func Dependencies(bytesIn []byte) ([]Dependency, error) {
+	root, err := parse(bytesIn)
+	if err != nil {
+		return nil, err
+	}
+
+	node := yamlnode.MappingValue(root, "dependencies")
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil, ErrorNoDependencies
+	}
+
+	var ret []Dependency
+
+	err = node.Decode(&ret)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse dependencies")
+	}
+
+	return ret, nil
+}
+
+// Update Update the versions of the `dependencies:` of a `Chart.yaml` to
+// the newest chart version of their repository that the policy allows,
+// keeping their constraint operator. Returns the updated file and the
+// versions the dependencies resolved to, for the `Chart.lock`.
+func Update(bytesIn []byte, repos *Repositories, policy versions.Policy) (string, []Dependency, error) {
+	root, err := parse(bytesIn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	node := yamlnode.MappingValue(root, "dependencies")
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return "", nil, ErrorNoDependencies
+	}
+
+	updates := map[*yaml.Node]string{}
+
+	var resolved []Dependency
+
+	for _, item := range node.Content {
+		name := yamlnode.MappingValue(item, "name")
+		repository := yamlnode.MappingValue(item, "repository")
+		version := yamlnode.MappingValue(item, "version")
+
+		if name == nil || repository == nil || version == nil || version.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		constraint, err := versions.ParseConstraint(version.Value)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"chart":   name.Value,
+				"version": version.Value,
+				"err":     err,
+			}).Debug("Cannot parse chart version")
+
+			continue
+		}
+
+		available, err := repos.Versions(repository.Value, name.Value)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"chart":      name.Value,
+				"repository": repository.Value,
+				"err":        err,
+			}).Error("Cannot list chart versions")
+
+			continue
+		}
+
+		latest, err := versions.Latest(policy.Filter(constraint.Version, available))
+		if err != nil {
+			continue
+		}
+
+		resolved = append(resolved, Dependency{
+			Name:       name.Value,
+			Repository: repository.Value,
+			Version:    latest,
+		})
+
+		if versions.Less(constraint.Version, latest) {
+			updates[version] = constraint.Bump(latest)
+		}
+	}
+
+	return yamlnode.Replace(bytesIn, updates), resolved, nil
+}
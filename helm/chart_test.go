@@ -0,0 +1,107 @@
+package helm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/versions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	server := testRepository(t)
+	defer server.Close()
+
+	in := heredoc.Doc(`
+		apiVersion: v2
+		name: app
+		version: 0.1.0
+		dependencies:
+		  - name: redis
+		    version: ~17.0.0 # cache
+		    repository: REPO
+		  - name: postgresql
+		    version: "12.1.2"
+		    repository: REPO
+		    condition: postgresql.enabled
+		  - name: nginx
+		    version: 15.0.0
+		    repository: oci://registry.example.com/charts
+	`)
+
+	var cases = []struct {
+		name     string
+		policy   versions.Policy
+		out      string
+		resolved []Dependency
+	}{
+		{
+			name:   "major",
+			policy: versions.PolicyMajor,
+			out: heredoc.Doc(`
+				apiVersion: v2
+				name: app
+				version: 0.1.0
+				dependencies:
+				  - name: redis
+				    version: ~18.1.0 # cache
+				    repository: REPO
+				  - name: postgresql
+				    version: "13.0.0"
+				    repository: REPO
+				    condition: postgresql.enabled
+				  - name: nginx
+				    version: 15.0.0
+				    repository: oci://registry.example.com/charts
+			`),
+			resolved: []Dependency{
+				{Name: "redis", Repository: "REPO", Version: "18.1.0"},
+				{Name: "postgresql", Repository: "REPO", Version: "13.0.0"},
+			},
+		},
+		{
+			name:   "minor",
+			policy: versions.PolicyMinor,
+			out: heredoc.Doc(`
+				apiVersion: v2
+				name: app
+				version: 0.1.0
+				dependencies:
+				  - name: redis
+				    version: ~17.3.2 # cache
+				    repository: REPO
+				  - name: postgresql
+				    version: "12.5.0"
+				    repository: REPO
+				    condition: postgresql.enabled
+				  - name: nginx
+				    version: 15.0.0
+				    repository: oci://registry.example.com/charts
+			`),
+			resolved: []Dependency{
+				{Name: "redis", Repository: "REPO", Version: "17.3.2"},
+				{Name: "postgresql", Repository: "REPO", Version: "12.5.0"},
+			},
+		},
+	}
+
+	repo := server.URL + "/stable"
+
+	for _, test := range cases {
+		out, resolved, err := Update([]byte(strings.ReplaceAll(in, "REPO", repo)), &Repositories{}, test.policy)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, strings.ReplaceAll(test.out, "REPO", repo), out, test.name)
+
+		for i := range test.resolved {
+			test.resolved[i].Repository = repo
+		}
+
+		assert.Equal(t, test.resolved, resolved, test.name)
+	}
+}
+
+func TestUpdateNoDependencies(t *testing.T) {
+	_, _, err := Update([]byte("apiVersion: v2\nname: app\n"), &Repositories{}, versions.PolicyMajor)
+	assert.Equal(t, ErrorNoDependencies, err)
+}
@@ -0,0 +1,88 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// now is replaced in the tests to have a stable `generated:` field.
+var now = time.Now
+
+type lock struct {
+	Dependencies []Dependency `yaml:"dependencies"`
+	Digest       string       `yaml:"digest"`
+	Generated    string       `yaml:"generated"`
+}
+
+// Digest Calculate the `digest:` of a `Chart.lock` the same way Helm does,
+// from the dependencies of `Chart.yaml` and the locked dependencies.
+func Digest(requested, locked []Dependency) (string, error) {
+	data, err := json.Marshal([2][]Dependency{requested, locked})
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal dependencies")
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+// UpdateLock Update the versions of a `Chart.lock` to the resolved versions
+// of the dependencies and recompute its digest from the updated
+// `Chart.yaml`. The lock file is returned as is if nothing changed.
+func UpdateLock(lockBytes, chartBytes []byte, resolved []Dependency) (string, error) {
+	var current lock
+
+	err := yaml.Unmarshal(lockBytes, &current)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse lock file")
+	}
+
+	requested, err := Dependencies(chartBytes)
+	if err != nil {
+		return "", err
+	}
+
+	changed := false
+
+	for i, locked := range current.Dependencies {
+		for _, dep := range resolved {
+			if dep.Name != locked.Name || dep.Repository != locked.Repository {
+				continue
+			}
+
+			if dep.Version != locked.Version {
+				current.Dependencies[i].Version = dep.Version
+				changed = true
+			}
+		}
+	}
+
+	digest, err := Digest(requested, current.Dependencies)
+	if err != nil {
+		return "", err
+	}
+
+	if !changed && digest == current.Digest {
+		return string(lockBytes), nil
+	}
+
+	var ret bytes.Buffer
+
+	fmt.Fprintln(&ret, "dependencies:")
+
+	for _, dep := range current.Dependencies {
+		fmt.Fprintf(&ret, "- name: %s\n", dep.Name)
+		fmt.Fprintf(&ret, "  repository: %s\n", dep.Repository)
+		fmt.Fprintf(&ret, "  version: %s\n", dep.Version)
+	}
+
+	fmt.Fprintf(&ret, "digest: %s\n", digest)
+	fmt.Fprintf(&ret, "generated: \"%s\"\n", now().UTC().Format(time.RFC3339Nano))
+
+	return ret.String(), nil
+}
@@ -0,0 +1,64 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLock(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+
+	chart := heredoc.Doc(`
+		apiVersion: v2
+		name: app
+		version: 0.1.0
+		dependencies:
+		  - name: redis
+		    version: ~17.3.2
+		    repository: https://charts.example.com/stable
+		  - name: postgresql
+		    version: "12.5.0"
+		    repository: https://charts.example.com/stable
+		    condition: postgresql.enabled
+	`)
+
+	lock := heredoc.Doc(`
+		dependencies:
+		- name: redis
+		  repository: https://charts.example.com/stable
+		  version: 17.0.0
+		- name: postgresql
+		  repository: https://charts.example.com/stable
+		  version: 12.1.2
+		digest: sha256:0000
+		generated: "2023-01-01T00:00:00Z"
+	`)
+
+	resolved := []Dependency{
+		{Name: "redis", Repository: "https://charts.example.com/stable", Version: "17.3.2"},
+		{Name: "postgresql", Repository: "https://charts.example.com/stable", Version: "12.5.0"},
+	}
+
+	out, err := UpdateLock([]byte(lock), []byte(chart), resolved)
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		dependencies:
+		- name: redis
+		  repository: https://charts.example.com/stable
+		  version: 17.3.2
+		- name: postgresql
+		  repository: https://charts.example.com/stable
+		  version: 12.5.0
+		digest: sha256:4b1ad4411edb85f593fb315014ac123d3552f3efc7b4fd2b530f99b7a5972486
+		generated: "2024-01-02T03:04:05Z"
+	`), out)
+
+	unchanged, err := UpdateLock([]byte(out), []byte(chart), resolved)
+	assert.Nil(t, err)
+	assert.Equal(t, out, unchanged)
+}
@@ -0,0 +1,177 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrorUnsupportedRepository = errors.New("repository is not an http(s) or file:// repository")
+	ErrorChartNotFound         = errors.New("chart not found in repository index")
+)
+
+// Repositories A client for the `index.yaml` of Helm chart repositories.
+type Repositories struct {
+	Client *http.Client
+	// Dir is the directory of the chart, that relative `file://`
+	// repositories are resolved against.
+	Dir     string
+	indexes map[string]*index
+}
+
+type index struct {
+	Entries map[string][]struct {
+		Version string `yaml:"version"`
+	} `yaml:"entries"`
+}
+
+func (r *Repositories) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+
+	return r.Client
+}
+
+func (r *Repositories) get(address string) ([]byte, error) {
+	resp, err := r.client().Get(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	return body, nil
+}
+
+// localPath Return the path of a `file://` repository.
+func (r *Repositories) localPath(repository string) string {
+	path := strings.TrimPrefix(repository, "file://")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, path)
+	}
+
+	return path
+}
+
+// index Fetch and cache the `index.yaml` of a repository. Local `file://`
+// repositories without an `index.yaml` are treated as a single chart
+// directory, as `helm dependency update` does.
+func (r *Repositories) index(repository string) (*index, error) {
+	if cached, ok := r.indexes[repository]; ok {
+		return cached, nil
+	}
+
+	var body []byte
+	var err error
+
+	switch {
+	case strings.HasPrefix(repository, "http://"), strings.HasPrefix(repository, "https://"):
+		var address *url.URL
+
+		address, err = url.Parse(strings.TrimSuffix(repository, "/") + "/index.yaml")
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s", repository)
+		}
+
+		body, err = r.get(address.String())
+	case strings.HasPrefix(repository, "file://"):
+		path := r.localPath(repository)
+
+		body, err = ioutil.ReadFile(filepath.Join(path, "index.yaml"))
+		if os.IsNotExist(err) {
+			return r.chartDir(repository, path)
+		}
+	default:
+		return nil, ErrorUnsupportedRepository
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ret index
+
+	err = yaml.Unmarshal(body, &ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the index of %s", repository)
+	}
+
+	r.cache(repository, &ret)
+
+	return &ret, nil
+}
+
+// chartDir Create an index for a local chart directory.
+func (r *Repositories) chartDir(repository, path string) (*index, error) {
+	body, err := ioutil.ReadFile(filepath.Join(path, ChartFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var chart struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	}
+
+	err = yaml.Unmarshal(body, &chart)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse %s", path)
+	}
+
+	var ret index
+
+	ret.Entries = map[string][]struct {
+		Version string `yaml:"version"`
+	}{
+		chart.Name: {{Version: chart.Version}},
+	}
+
+	r.cache(repository, &ret)
+
+	return &ret, nil
+}
+
+func (r *Repositories) cache(repository string, value *index) {
+	if r.indexes == nil {
+		r.indexes = map[string]*index{}
+	}
+
+	r.indexes[repository] = value
+}
+
+// Versions List the available versions of a chart in a repository.
+func (r *Repositories) Versions(repository, name string) ([]string, error) {
+	index, err := r.index(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := index.Entries[name]
+	if !ok {
+		return nil, ErrorChartNotFound
+	}
+
+	var ret []string
+	for _, entry := range entries {
+		ret = append(ret, entry.Version)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,90 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCharts = map[string][]string{
+	"redis":      {"17.0.0", "17.3.2", "18.1.0", "18.2.0-rc.1"},
+	"postgresql": {"12.1.2", "12.5.0", "13.0.0"},
+}
+
+// testRepository A local stand-in of a chart repository served under
+// `/stable`.
+func testRepository(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stable/index.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprintln(w, "apiVersion: v1")
+		fmt.Fprintln(w, "entries:")
+
+		for name, versions := range testCharts {
+			fmt.Fprintf(w, "  %s:\n", name)
+
+			for _, version := range versions {
+				fmt.Fprintf(w, "    - name: %s\n      version: %s\n", name, version)
+			}
+		}
+	}))
+}
+
+func TestVersions(t *testing.T) {
+	server := testRepository(t)
+	defer server.Close()
+
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, ChartFile), []byte("name: common\nversion: 1.2.0\n"), 0644)
+	assert.Nil(t, err)
+
+	var cases = []struct {
+		name       string
+		repository string
+		chart      string
+		out        []string
+		err        error
+	}{
+		{
+			name:       "http repository",
+			repository: server.URL + "/stable/",
+			chart:      "postgresql",
+			out:        testCharts["postgresql"],
+		},
+		{
+			name:       "chart missing from the index",
+			repository: server.URL + "/stable",
+			chart:      "mysql",
+			err:        ErrorChartNotFound,
+		},
+		{
+			name:       "local chart directory",
+			repository: "file://" + filepath.Base(dir),
+			chart:      "common",
+			out:        []string{"1.2.0"},
+		},
+		{
+			name:       "oci repository",
+			repository: "oci://registry.example.com/charts",
+			chart:      "redis",
+			err:        ErrorUnsupportedRepository,
+		},
+	}
+
+	repos := Repositories{Dir: filepath.Dir(dir)}
+
+	for _, test := range cases {
+		versions, err := repos.Versions(test.repository, test.chart)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, versions, test.name)
+	}
+}
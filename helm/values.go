@@ -0,0 +1,109 @@
+package helm
+
+import (
+	"github.com/mhristof/zoi/docker"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/yamlnode"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const ValuesFile = "values.yaml"
+
+var (
+	ErrorNoAppVersionImage = errors.New("no `image:` block using the `appVersion` as its tag")
+)
+
+// imageName Return the image of a `values.yaml` image block, made of its
+// `registry:` and `repository:` fields.
+func imageName(node *yaml.Node) string {
+	repository := yamlnode.MappingValue(node, "repository")
+	if repository == nil || repository.Kind != yaml.ScalarNode || repository.Value == "" {
+		return ""
+	}
+
+	if registry := yamlnode.MappingValue(node, "registry"); registry != nil && registry.Value != "" {
+		return registry.Value + "/" + repository.Value
+	}
+
+	return repository.Value
+}
+
+// newestTag Find the newest tag of the image that has the same shape as
+// the current tag.
+func newestTag(image, tag string, registry *docker.Registry) string {
+	ref, err := docker.ParseReference(image + ":" + tag)
+	if err != nil {
+		return tag
+	}
+
+	tags, err := registry.Tags(ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"image": image,
+		}).Error("Cannot list image tags")
+
+		return tag
+	}
+
+	return docker.NewestTag(tag, tags)
+}
+
+func walkValues(node *yaml.Node, registry *docker.Registry, updates map[*yaml.Node]string) {
+	if image := imageName(node); image != "" {
+		tag := yamlnode.MappingValue(node, "tag")
+		if tag != nil && tag.Kind == yaml.ScalarNode && tag.Value != "" {
+			updates[tag] = newestTag(image, tag.Value, registry)
+		}
+	}
+
+	for _, child := range node.Content {
+		walkValues(child, registry, updates)
+	}
+}
+
+// UpdateValues Update the `tag:` of the image blocks of a `values.yaml` to
+// the newest tag of the same shape.
+func UpdateValues(bytesIn []byte, registry *docker.Registry) (string, error) {
+	root, err := parse(bytesIn)
+	if err != nil {
+		return "", err
+	}
+
+	updates := map[*yaml.Node]string{}
+	walkValues(root, registry, updates)
+
+	return yamlnode.Replace(bytesIn, updates), nil
+}
+
+// UpdateAppVersion Update the `appVersion` of a `Chart.yaml` to the newest
+// tag of the top level `image:` of the `values.yaml`, when the image has no
+// `tag:` and uses the `appVersion` instead, as `helm create` does.
+func UpdateAppVersion(chartBytes, valuesBytes []byte, registry *docker.Registry) (string, error) {
+	chart, err := parse(chartBytes)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := parse(valuesBytes)
+	if err != nil {
+		return "", err
+	}
+
+	appVersion := yamlnode.MappingValue(chart, "appVersion")
+	image := yamlnode.MappingValue(values, "image")
+
+	if appVersion == nil || appVersion.Kind != yaml.ScalarNode || image == nil {
+		return "", ErrorNoAppVersionImage
+	}
+
+	name := imageName(image)
+	if tag := yamlnode.MappingValue(image, "tag"); name == "" || (tag != nil && tag.Value != "") {
+		return "", ErrorNoAppVersionImage
+	}
+
+	return yamlnode.Replace(chartBytes, map[*yaml.Node]string{
+		appVersion: newestTag(name, appVersion.Value, registry),
+	}), nil
+}
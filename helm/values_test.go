@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRegistry A local stand-in of a registry without authentication.
+func testRegistry(t *testing.T) *httptest.Server {
+	images := map[string][]string{
+		"/v2/library/nginx/tags/list": {"1.25.3", "1.25.4", "1.26.0", "latest"},
+		"/v2/bitnami/redis/tags/list": {"7.2.3-debian-11-r0", "7.2.4-debian-11-r1", "7.2.4"},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tags, ok := images[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+	}))
+}
+
+func TestUpdateValues(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	in := heredoc.Doc(`
+		image:
+		  repository: nginx
+		  tag: ""
+		redis:
+		  image:
+		    registry: docker.io
+		    repository: bitnami/redis
+		    tag: 7.2.3-debian-11-r0 # pinned
+	`)
+
+	out, err := UpdateValues([]byte(in), &docker.Registry{URL: server.URL})
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		image:
+		  repository: nginx
+		  tag: ""
+		redis:
+		  image:
+		    registry: docker.io
+		    repository: bitnami/redis
+		    tag: 7.2.4-debian-11-r1 # pinned
+	`), out)
+}
+
+func TestUpdateAppVersion(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name   string
+		values string
+		out    string
+		err    error
+	}{
+		{
+			name:   "image without a tag",
+			values: "image:\n  repository: nginx\n  tag: \"\"\n",
+			out:    "apiVersion: v2\nname: app\nappVersion: \"1.26.0\"\n",
+		},
+		{
+			name:   "image with a tag",
+			values: "image:\n  repository: nginx\n  tag: 1.25.3\n",
+			err:    ErrorNoAppVersionImage,
+		},
+	}
+
+	chart := "apiVersion: v2\nname: app\nappVersion: \"1.25.3\"\n"
+
+	for _, test := range cases {
+		out, err := UpdateAppVersion([]byte(chart), []byte(test.values), &docker.Registry{URL: server.URL})
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
@@ -0,0 +1,210 @@
+package homebrew
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNotFormula = errors.New("no `class ... < Formula` found")
+)
+
+var (
+	formulaRe = regexp.MustCompile(`(?m)^\s*class\s+\w+\s*<\s*Formula\b`)
+	urlRe     = regexp.MustCompile(`^(\s*url\s+")([^"]+)(".*)$`)
+	sha256Re  = regexp.MustCompile(`^(\s*sha256\s+")([0-9a-fA-F]{64})(".*)$`)
+	versionRe = regexp.MustCompile(`^(\s*version\s+")([^"]+)(".*)$`)
+	endRe     = regexp.MustCompile(`^\s*(end|.*\bdo)\s*(#.*)?$`)
+)
+
+// IsFormula Check if a file name looks like a Homebrew formula.
+func IsFormula(file string) bool {
+	return filepath.Ext(file) == ".rb"
+}
+
+// stanza A `url` of a formula and the line of its `sha256`, which is -1
+// when the url has no checksum.
+type stanza struct {
+	line   int
+	sha256 int
+	owner  string
+	repo   string
+	tag    string
+}
+
+// stanzas Find the GitHub `url` stanzas of a formula, including the ones of
+// `on_macos`, `on_linux` and the CPU blocks, and their `sha256`.
+func stanzas(lines []string) []stanza {
+	var ret []stanza
+
+	for i, line := range lines {
+		found := urlRe.FindStringSubmatch(line)
+		if found == nil {
+			continue
+		}
+
+		release, err := gh.ParseReleaseUrl(found[2])
+		if err != nil {
+			continue
+		}
+
+		s := stanza{line: i, sha256: -1, owner: release.Owner, repo: release.Repo, tag: release.Release}
+
+		for j := i + 1; j < len(lines); j++ {
+			if urlRe.MatchString(lines[j]) || endRe.MatchString(lines[j]) {
+				break
+			}
+
+			if sha256Re.MatchString(lines[j]) {
+				s.sha256 = j
+
+				break
+			}
+		}
+
+		ret = append(ret, s)
+	}
+
+	return ret
+}
+
+// update The new url and sha256 of a stanza.
+type update struct {
+	url    string
+	sha256 string
+}
+
+// updateRepo Resolve the next release of all the urls of a repository. The
+// urls are left as they are unless they all move to the same tag and all
+// the new checksums are found, so that the per-arch blocks stay in step.
+func updateRepo(lines []string, found []stanza, fetcher *checksum.Fetcher, resolve gh.Resolver) (string, map[int]update) {
+	ret := map[int]update{}
+	next := ""
+
+	for _, s := range found {
+		url := urlRe.FindStringSubmatch(lines[s.line])[2]
+
+		updated, err := resolve(&gh.Url{
+			Host:    "https://github.com",
+			Owner:   s.owner,
+			Repo:    s.repo,
+			Release: s.tag,
+			Url:     url,
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err": err,
+				"url": url,
+			}).Error("Cannot find the next release")
+
+			return "", nil
+		}
+
+		tag := ""
+		if release, err := gh.ParseReleaseUrl(updated); err == nil {
+			tag = release.Release
+		}
+
+		if next != "" && tag != next {
+			log.WithFields(log.Fields{
+				"repo": s.owner + "/" + s.repo,
+				"tags": []string{next, tag},
+			}).Warning("Formula urls resolve to different releases, leaving them as they are")
+
+			return "", nil
+		}
+
+		next = tag
+
+		if updated == url {
+			continue
+		}
+
+		u := update{url: updated}
+
+		if s.sha256 >= 0 {
+			u.sha256, err = fetcher.SHA256(updated)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"err": err,
+					"url": updated,
+				}).Warning("Cannot find the checksum of the new url, leaving the formula as it is")
+
+				return "", nil
+			}
+		}
+
+		ret[s.line] = u
+	}
+
+	return next, ret
+}
+
+// Update Update the GitHub `url` stanzas of a Homebrew formula to the next
+// release, with their `sha256` and the `version` of the formula. Bottles
+// are left to be rebuilt by the tap.
+func Update(bytesIn []byte, fetcher *checksum.Fetcher, resolve gh.Resolver) (string, error) {
+	if !formulaRe.Match(bytesIn) {
+		return "", ErrorNotFormula
+	}
+
+	lines := strings.Split(string(bytesIn), "\n")
+
+	repos := map[string][]stanza{}
+	var order []string
+
+	for _, s := range stanzas(lines) {
+		repo := s.owner + "/" + s.repo
+		if _, ok := repos[repo]; !ok {
+			order = append(order, repo)
+		}
+
+		repos[repo] = append(repos[repo], s)
+	}
+
+	// versions The old and the new tags of the updated repositories.
+	versions := map[string]string{}
+
+	for _, repo := range order {
+		next, updates := updateRepo(lines, repos[repo], fetcher, resolve)
+		if len(updates) == 0 {
+			continue
+		}
+
+		for _, s := range repos[repo] {
+			u, ok := updates[s.line]
+			if !ok {
+				continue
+			}
+
+			found := urlRe.FindStringSubmatch(lines[s.line])
+			lines[s.line] = found[1] + u.url + found[3]
+
+			if s.sha256 >= 0 {
+				found = sha256Re.FindStringSubmatch(lines[s.sha256])
+				lines[s.sha256] = found[1] + u.sha256 + found[3]
+			}
+
+			versions[strings.TrimPrefix(s.tag, "v")] = strings.TrimPrefix(next, "v")
+		}
+	}
+
+	for i, line := range lines {
+		found := versionRe.FindStringSubmatch(line)
+		if found == nil {
+			continue
+		}
+
+		if next, ok := versions[strings.TrimPrefix(found[2], "v")]; ok {
+			lines[i] = found[1] + strings.Replace(found[2], strings.TrimPrefix(found[2], "v"), next, 1) + found[3]
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,189 @@
+package homebrew
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFiles = map[string]string{
+	"/org/tool/archive/refs/tags/v1.3.0.tar.gz":                          "tool source",
+	"/org/cli/releases/download/v2.1.0/cli_2.1.0_darwin_arm64.tar.gz":    "cli darwin arm64",
+	"/org/cli/releases/download/v2.1.0/cli_2.1.0_darwin_amd64.tar.gz":    "cli darwin amd64",
+	"/org/cli/releases/download/v2.1.0/cli_2.1.0_linux_amd64.tar.gz":     "cli linux amd64",
+	"/org/partial/releases/download/v0.2.0/partial_0.2.0_darwin.tar.gz":  "partial darwin",
+	"/org/partial/releases/download/v0.2.0/partial_0.2.0_missing.tar.gz": "",
+}
+
+// testServer A local stand-in of the GitHub archives and release assets.
+func testServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contents, ok := testFiles[r.URL.Path]; ok && contents != "" {
+			fmt.Fprint(w, contents)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"org/tool":    "v1.3.0",
+	"org/cli":     "v2.1.0",
+	"org/partial": "v0.2.0",
+})
+
+func sum(contents string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+}
+
+func TestUpdate(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	old := strings.Repeat("0", 64)
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "source archive",
+			in: heredoc.Doc(`
+				class Tool < Formula
+				  desc "A tool"
+				  homepage "https://github.com/org/tool"
+				  url "https://github.com/org/tool/archive/refs/tags/v1.2.3.tar.gz"
+				  sha256 "OLD"
+				  version "1.2.3"
+				  license "MIT"
+				  head "https://github.com/org/tool.git", branch: "main"
+
+				  bottle do
+				    sha256 cellar: :any, arm64_sonoma: "OLD"
+				  end
+				end
+			`),
+			out: heredoc.Doc(`
+				class Tool < Formula
+				  desc "A tool"
+				  homepage "https://github.com/org/tool"
+				  url "https://github.com/org/tool/archive/refs/tags/v1.3.0.tar.gz"
+				  sha256 "TOOL"
+				  version "1.3.0"
+				  license "MIT"
+				  head "https://github.com/org/tool.git", branch: "main"
+
+				  bottle do
+				    sha256 cellar: :any, arm64_sonoma: "OLD"
+				  end
+				end
+			`),
+		},
+		{
+			name: "per-arch blocks",
+			in: heredoc.Doc(`
+				class Cli < Formula
+				  version "2.0.0"
+
+				  on_macos do
+				    if Hardware::CPU.arm?
+				      url "https://github.com/org/cli/releases/download/v2.0.0/cli_2.0.0_darwin_arm64.tar.gz"
+				      sha256 "OLD"
+				    end
+				    on_intel do
+				      url "https://github.com/org/cli/releases/download/v2.0.0/cli_2.0.0_darwin_amd64.tar.gz"
+				      sha256 "OLD"
+				    end
+				  end
+
+				  on_linux do
+				    url "https://github.com/org/cli/releases/download/v2.0.0/cli_2.0.0_linux_amd64.tar.gz"
+				    sha256 "OLD" # linux
+				  end
+				end
+			`),
+			out: heredoc.Doc(`
+				class Cli < Formula
+				  version "2.1.0"
+
+				  on_macos do
+				    if Hardware::CPU.arm?
+				      url "https://github.com/org/cli/releases/download/v2.1.0/cli_2.1.0_darwin_arm64.tar.gz"
+				      sha256 "DARWIN_ARM64"
+				    end
+				    on_intel do
+				      url "https://github.com/org/cli/releases/download/v2.1.0/cli_2.1.0_darwin_amd64.tar.gz"
+				      sha256 "DARWIN_AMD64"
+				    end
+				  end
+
+				  on_linux do
+				    url "https://github.com/org/cli/releases/download/v2.1.0/cli_2.1.0_linux_amd64.tar.gz"
+				    sha256 "LINUX_AMD64" # linux
+				  end
+				end
+			`),
+		},
+		{
+			name: "missing checksum leaves all the blocks",
+			in: heredoc.Doc(`
+				class Partial < Formula
+				  version "0.1.0"
+				  on_macos do
+				    url "https://github.com/org/partial/releases/download/v0.1.0/partial_0.1.0_darwin.tar.gz"
+				    sha256 "OLD"
+				  end
+				  on_linux do
+				    url "https://github.com/org/partial/releases/download/v0.1.0/partial_0.1.0_missing.tar.gz"
+				    sha256 "OLD"
+				  end
+				end
+			`),
+			out: heredoc.Doc(`
+				class Partial < Formula
+				  version "0.1.0"
+				  on_macos do
+				    url "https://github.com/org/partial/releases/download/v0.1.0/partial_0.1.0_darwin.tar.gz"
+				    sha256 "OLD"
+				  end
+				  on_linux do
+				    url "https://github.com/org/partial/releases/download/v0.1.0/partial_0.1.0_missing.tar.gz"
+				    sha256 "OLD"
+				  end
+				end
+			`),
+		},
+		{
+			name: "not a formula",
+			in:   "puts 'hello'\n",
+			err:  ErrorNotFormula,
+		},
+	}
+
+	replacer := strings.NewReplacer(
+		"OLD", old,
+		"TOOL", sum("tool source"),
+		"DARWIN_ARM64", sum("cli darwin arm64"),
+		"DARWIN_AMD64", sum("cli darwin amd64"),
+		"LINUX_AMD64", sum("cli linux amd64"),
+	)
+
+	for _, test := range cases {
+		out, err := Update([]byte(replacer.Replace(test.in)), &checksum.Fetcher{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, replacer.Replace(test.out), out, test.name)
+	}
+}
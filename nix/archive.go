@@ -0,0 +1,64 @@
+package nix
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mhristof/zoi/checksum"
+	"github.com/pkg/errors"
+)
+
+// Archives Download GitHub source archives and hash them like Nix does.
+type Archives struct {
+	// URL overrides https://github.com, for example to use a local mirror.
+	URL    string
+	Client *http.Client
+	hashes map[string][]byte
+}
+
+func (a *Archives) client() *http.Client {
+	if a.Client == nil {
+		return http.DefaultClient
+	}
+
+	return a.Client
+}
+
+// Hash Return the NAR hash of the source archive of a GitHub repository
+// at a rev, which is either a tag or a commit.
+func (a *Archives) Hash(owner, repo, rev string) ([]byte, error) {
+	base := checksum.GithubURL
+	if a.URL != "" {
+		base = strings.TrimSuffix(a.URL, "/")
+	}
+
+	address := fmt.Sprintf("%s/%s/%s/archive/%s.tar.gz", base, owner, repo, rev)
+
+	if sum, ok := a.hashes[address]; ok {
+		return sum, nil
+	}
+
+	resp, err := a.client().Get(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	sum, err := NarHash(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot hash %s", address)
+	}
+
+	if a.hashes == nil {
+		a.hashes = map[string][]byte{}
+	}
+
+	a.hashes[address] = sum
+
+	return sum, nil
+}
@@ -0,0 +1,217 @@
+package nix
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoFetchers = errors.New("no fetchFromGitHub found")
+)
+
+var (
+	fetcherRe   = regexp.MustCompile(`\bfetchFromGitHub\s*\{`)
+	attributeRe = regexp.MustCompile(`\b(owner|repo|rev|tag|hash|sha256)\s*=\s*"([^"]*)"\s*;`)
+	unsupported = regexp.MustCompile(`\b(fetchSubmodules|leaveDotGit|deepClone|forceFetchGit)\s*=\s*true\b`)
+	versionRe   = regexp.MustCompile(`\bversion\s*=\s*"([^"$]*)"\s*;`)
+	commitRe    = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// IsNix Check if a file name looks like a Nix expression.
+func IsNix(file string) bool {
+	return filepath.Ext(file) == ".nix"
+}
+
+// value A string value of a Nix file, with the position of its contents
+// between the quotes.
+type value struct {
+	start int
+	end   int
+	text  string
+}
+
+// edit A replacement of a part of a file.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// blockEnd Find the closing brace of the attribute set that opens at pos.
+func blockEnd(src string, pos int) int {
+	depth := 0
+
+	for i := pos; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(src)
+}
+
+// attributes Find the string attributes of a fetchFromGitHub call.
+func attributes(src string, start, end int) map[string]value {
+	ret := map[string]value{}
+
+	for _, found := range attributeRe.FindAllStringSubmatchIndex(src[start:end], -1) {
+		ret[src[start+found[2]:start+found[3]]] = value{
+			start: start + found[4],
+			end:   start + found[5],
+			text:  src[start+found[4] : start+found[5]],
+		}
+	}
+
+	return ret
+}
+
+// versionBefore Find the last `version = "...";` before pos, which
+// `rev = "v${version}";` refers to.
+func versionBefore(src string, pos int) (value, bool) {
+	found := versionRe.FindAllStringSubmatchIndex(src[0:pos], -1)
+	if len(found) == 0 {
+		return value{}, false
+	}
+
+	last := found[len(found)-1]
+
+	return value{start: last[2], end: last[3], text: src[last[2]:last[3]]}, true
+}
+
+// updateFetcher Update the `rev` or `tag` of a fetchFromGitHub call to the
+// next release, or its commit to the commit of the latest tag, and
+// recompute its hash. The call is left as it is if the new archive cannot
+// be hashed, as the old hash would break the build.
+func updateFetcher(src string, start, end int, archives *Archives, resolve gh.Resolver, repos gh.Repositories) []edit {
+	if unsupported.MatchString(src[start:end]) {
+		log.WithFields(log.Fields{
+			"fetcher": src[start:end],
+		}).Debug("fetchFromGitHub is not a plain archive")
+
+		return nil
+	}
+
+	attrs := attributes(src, start, end)
+	owner, repo := attrs["owner"].text, attrs["repo"].text
+
+	ref, ok := attrs["tag"]
+	if !ok {
+		ref, ok = attrs["rev"]
+	}
+
+	if !ok || owner == "" || repo == "" || strings.Contains(owner+repo, "$") {
+		return nil
+	}
+
+	var ret []edit
+	var next string
+
+	switch {
+	case commitRe.MatchString(ref.text):
+		_, sha, moved := gh.NextCommit(repos, owner, repo, ref.text)
+		if !moved {
+			return nil
+		}
+
+		next = sha
+		ret = append(ret, edit{ref.start, ref.end, sha})
+	case strings.Count(ref.text, "${version}") == 1:
+		version, ok := versionBefore(src, start)
+		if !ok {
+			return nil
+		}
+
+		parts := strings.SplitN(ref.text, "${version}", 2)
+		tag := parts[0] + version.text + parts[1]
+
+		next = resolve.Next(owner, repo, tag)
+		if next == tag || !strings.HasPrefix(next, parts[0]) || !strings.HasSuffix(next, parts[1]) {
+			return nil
+		}
+
+		ret = append(ret, edit{version.start, version.end, strings.TrimSuffix(strings.TrimPrefix(next, parts[0]), parts[1])})
+	case !strings.Contains(ref.text, "$"):
+		next = resolve.Next(owner, repo, ref.text)
+		if next == ref.text {
+			return nil
+		}
+
+		ret = append(ret, edit{ref.start, ref.end, next})
+	default:
+		return nil
+	}
+
+	sum, err := archives.Hash(owner, repo, next)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":  err,
+			"repo": owner + "/" + repo,
+			"rev":  next,
+		}).Warning("Cannot hash the new archive, leaving it as it is")
+
+		return nil
+	}
+
+	if hash, ok := attrs["hash"]; ok {
+		ret = append(ret, edit{hash.start, hash.end, SRI(sum)})
+	}
+
+	if hash, ok := attrs["sha256"]; ok {
+		updated := Base32(sum)
+		if strings.HasPrefix(hash.text, "sha256-") {
+			updated = SRI(sum)
+		}
+
+		ret = append(ret, edit{hash.start, hash.end, updated})
+	}
+
+	return ret
+}
+
+// Update Update the fetchFromGitHub calls of a Nix expression, including
+// the `version` that a `rev = "v${version}";` refers to.
+func Update(bytesIn []byte, archives *Archives, resolve gh.Resolver, repos gh.Repositories) (string, error) {
+	src := string(bytesIn)
+
+	found := fetcherRe.FindAllStringIndex(src, -1)
+	if len(found) == 0 {
+		return "", ErrorNoFetchers
+	}
+
+	// edits by position, as fetchers can share a version
+	edits := map[int]edit{}
+
+	for _, fetcher := range found {
+		start := fetcher[1] - 1
+
+		for _, e := range updateFetcher(src, start, blockEnd(src, start), archives, resolve, repos) {
+			edits[e.start] = e
+		}
+	}
+
+	positions := make([]int, 0, len(edits))
+	for pos := range edits {
+		positions = append(positions, pos)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+
+	for _, pos := range positions {
+		e := edits[pos]
+		src = src[0:e.start] + e.text + src[e.end:]
+	}
+
+	return src, nil
+}
@@ -0,0 +1,208 @@
+package nix
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testOldCommit = "1111111111111111111111111111111111111111"
+	testNewCommit = "2222222222222222222222222222222222222222"
+)
+
+// testArchives The source archives of the local GitHub stand-in.
+func testArchives(t *testing.T) map[string][]byte {
+	ret := map[string][]byte{}
+
+	for _, path := range []string{
+		"/BurntSushi/ripgrep/archive/14.1.0.tar.gz",
+		"/sharkdp/fd/archive/v9.0.0.tar.gz",
+		"/owner/lib/archive/" + testNewCommit + ".tar.gz",
+		"/NixOS/nixpkgs/archive/" + testNewCommit + ".tar.gz",
+	} {
+		ret[path] = testArchive(t, []testFile{
+			{name: "source/", mode: 0755},
+			{name: "source/path", contents: path, mode: 0644},
+		})
+	}
+
+	return ret
+}
+
+// testServer A local stand-in of the GitHub source archives.
+func testServer(t *testing.T) (*httptest.Server, map[string][]byte) {
+	archives := testArchives(t)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if archive, ok := archives[r.URL.Path]; ok {
+			w.Write(archive)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	})), archives
+}
+
+// testHash Return the hash of an archive of the server.
+func testHash(t *testing.T, archives map[string][]byte, path string) []byte {
+	sum, err := NarHash(bytes.NewReader(archives[path]))
+	assert.Nil(t, err)
+
+	return sum
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"BurntSushi/ripgrep": "14.1.0",
+	"sharkdp/fd":         "v9.0.0",
+	"owner/missing":      "v2.0.0",
+})
+
+// testRepos A stand-in of the GitHub tags and commits.
+type testRepos struct{}
+
+func (testRepos) LatestTag(owner, repo string) (string, string, error) {
+	return "v1.0.0", testNewCommit, nil
+}
+
+func (testRepos) Ahead(owner, repo, base, head string) (bool, error) {
+	return true, nil
+}
+
+func TestUpdate(t *testing.T) {
+	server, archives := testServer(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "repo is not a string",
+			in: heredoc.Doc(`
+				{ lib, rustPlatform, fetchFromGitHub }:
+
+				rustPlatform.buildRustPackage rec {
+				  pname = "ripgrep";
+				  version = "13.0.0";
+
+				  src = fetchFromGitHub {
+				    owner = "BurntSushi";
+				    repo = pname;
+				    rev = "${version}";
+				    hash = "sha256-AAAA";
+				  };
+				}
+			`),
+			out: heredoc.Doc(`
+				{ lib, rustPlatform, fetchFromGitHub }:
+
+				rustPlatform.buildRustPackage rec {
+				  pname = "ripgrep";
+				  version = "13.0.0";
+
+				  src = fetchFromGitHub {
+				    owner = "BurntSushi";
+				    repo = pname;
+				    rev = "${version}";
+				    hash = "sha256-AAAA";
+				  };
+				}
+			`),
+		},
+		{
+			name: "version, tag, commit and legacy sha256",
+			in: heredoc.Doc(`
+				{
+				  version = "8.7.0";
+				  fd = fetchFromGitHub {
+				    owner = "sharkdp";
+				    repo = "fd";
+				    rev = "v${version}";
+				    hash = "sha256-AAAA";
+				  };
+				  rg = fetchFromGitHub { owner = "BurntSushi"; repo = "ripgrep"; tag = "13.0.0"; sha256 = "0000"; };
+				  lib = fetchFromGitHub {
+				    owner = "owner";
+				    repo = "lib";
+				    rev = "TESTOLDCOMMIT";
+				    hash = "sha256-AAAA";
+				  };
+				  missing = fetchFromGitHub {
+				    owner = "owner";
+				    repo = "missing";
+				    rev = "v1.0.0";
+				    hash = "sha256-AAAA";
+				  };
+				  submodules = fetchFromGitHub {
+				    owner = "sharkdp";
+				    repo = "fd";
+				    rev = "v8.7.0";
+				    fetchSubmodules = true;
+				    hash = "sha256-AAAA";
+				  };
+				}
+			`),
+			out: heredoc.Doc(`
+				{
+				  version = "9.0.0";
+				  fd = fetchFromGitHub {
+				    owner = "sharkdp";
+				    repo = "fd";
+				    rev = "v${version}";
+				    hash = "FD";
+				  };
+				  rg = fetchFromGitHub { owner = "BurntSushi"; repo = "ripgrep"; tag = "14.1.0"; sha256 = "RG32"; };
+				  lib = fetchFromGitHub {
+				    owner = "owner";
+				    repo = "lib";
+				    rev = "TESTNEWCOMMIT";
+				    hash = "LIB";
+				  };
+				  missing = fetchFromGitHub {
+				    owner = "owner";
+				    repo = "missing";
+				    rev = "v1.0.0";
+				    hash = "sha256-AAAA";
+				  };
+				  submodules = fetchFromGitHub {
+				    owner = "sharkdp";
+				    repo = "fd";
+				    rev = "v8.7.0";
+				    fetchSubmodules = true;
+				    hash = "sha256-AAAA";
+				  };
+				}
+			`),
+		},
+		{
+			name: "no fetchers",
+			in:   "{ pkgs }: pkgs.hello\n",
+			err:  ErrorNoFetchers,
+		},
+	}
+
+	replacer := strings.NewReplacer(
+		"TESTOLDCOMMIT", testOldCommit,
+		"TESTNEWCOMMIT", testNewCommit,
+		"FD", SRI(testHash(t, archives, "/sharkdp/fd/archive/v9.0.0.tar.gz")),
+		"RG32", Base32(testHash(t, archives, "/BurntSushi/ripgrep/archive/14.1.0.tar.gz")),
+		"LIB", SRI(testHash(t, archives, "/owner/lib/archive/"+testNewCommit+".tar.gz")),
+	)
+
+	for _, test := range cases {
+		out, err := Update([]byte(replacer.Replace(test.in)), &Archives{URL: server.URL}, testResolve, testRepos{})
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, replacer.Replace(test.out), out, test.name)
+	}
+}
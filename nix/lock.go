@@ -0,0 +1,141 @@
+package nix
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+const LockFile = "flake.lock"
+
+var (
+	ErrorNotLock = errors.New("no `nodes` found in flake.lock")
+)
+
+// Commits Find the commit of a ref of a GitHub repository and its date,
+// like gh.Client does.
+type Commits interface {
+	Commit(owner, repo, ref string) (string, time.Time, error)
+}
+
+// lockString Return a string field of a flake.lock object.
+func lockString(object map[string]interface{}, key string) string {
+	ret, _ := object[key].(string)
+
+	return ret
+}
+
+// updateNode Lock a `github:` input to the latest commit of the ref it
+// follows, like `nix flake update` does. Inputs that point to a tag or to
+// a commit in flake.nix are left as they are.
+func updateNode(name string, node map[string]interface{}, archives *Archives, commits Commits) bool {
+	locked, _ := node["locked"].(map[string]interface{})
+	original, _ := node["original"].(map[string]interface{})
+
+	if locked == nil || original == nil || lockString(locked, "type") != "github" || lockString(original, "type") != "github" {
+		return false
+	}
+
+	ref := lockString(original, "ref")
+	if _, err := versions.Parse(ref); err == nil || lockString(original, "rev") != "" {
+		log.WithFields(log.Fields{
+			"input": name,
+		}).Debug("Input is pinned in flake.nix")
+
+		return false
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	owner, repo := lockString(locked, "owner"), lockString(locked, "repo")
+
+	sha, date, err := commits.Commit(owner, repo, ref)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"input": name,
+		}).Error("Cannot find the latest commit")
+
+		return false
+	}
+
+	if sha == lockString(locked, "rev") {
+		return false
+	}
+
+	sum, err := archives.Hash(owner, repo, sha)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"input": name,
+		}).Warning("Cannot hash the new archive, leaving it as it is")
+
+		return false
+	}
+
+	locked["rev"] = sha
+	locked["narHash"] = SRI(sum)
+	locked["lastModified"] = date.Unix()
+
+	return true
+}
+
+// UpdateLock Update the `github:` inputs of a `flake.lock` to the latest
+// commit of their ref, with their `narHash` and `lastModified`.
+func UpdateLock(bytesIn []byte, archives *Archives, commits Commits) (string, error) {
+	var lock map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(bytesIn))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&lock)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse flake.lock")
+	}
+
+	nodes, ok := lock["nodes"].(map[string]interface{})
+	if !ok {
+		return "", ErrorNotLock
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	changed := false
+
+	for _, name := range names {
+		node, ok := nodes[name].(map[string]interface{})
+		if ok && updateNode(name, node, archives, commits) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return string(bytesIn), nil
+	}
+
+	var ret bytes.Buffer
+
+	// nix writes flake.lock with sorted keys and 2 spaces, same as Go
+	encoder := json.NewEncoder(&ret)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(lock)
+	if err != nil {
+		return "", err
+	}
+
+	return ret.String(), nil
+}
@@ -0,0 +1,125 @@
+package nix
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCommits A stand-in of the GitHub commits.
+type testCommits struct{}
+
+func (testCommits) Commit(owner, repo, ref string) (string, time.Time, error) {
+	if owner+"/"+repo+"@"+ref != "NixOS/nixpkgs@nixos-unstable" {
+		return "", time.Time{}, fmt.Errorf("unknown ref %s/%s@%s", owner, repo, ref)
+	}
+
+	return testNewCommit, time.Unix(1710000000, 0), nil
+}
+
+func TestUpdateLock(t *testing.T) {
+	server, archives := testServer(t)
+	defer server.Close()
+
+	in := heredoc.Docf(`
+		{
+		  "nodes": {
+		    "nixpkgs": {
+		      "locked": {
+		        "lastModified": 1700000000,
+		        "narHash": "sha256-AAAA",
+		        "owner": "NixOS",
+		        "repo": "nixpkgs",
+		        "rev": "%s",
+		        "type": "github"
+		      },
+		      "original": {
+		        "owner": "NixOS",
+		        "ref": "nixos-unstable",
+		        "repo": "nixpkgs",
+		        "type": "github"
+		      }
+		    },
+		    "root": {
+		      "inputs": {
+		        "nixpkgs": "nixpkgs",
+		        "utils": "utils"
+		      }
+		    },
+		    "utils": {
+		      "locked": {
+		        "lastModified": 1700000000,
+		        "narHash": "sha256-BBBB",
+		        "owner": "numtide",
+		        "repo": "flake-utils",
+		        "rev": "%s",
+		        "type": "github"
+		      },
+		      "original": {
+		        "owner": "numtide",
+		        "ref": "v1.0.0",
+		        "repo": "flake-utils",
+		        "type": "github"
+		      }
+		    }
+		  },
+		  "root": "root",
+		  "version": 7
+		}
+	`, testOldCommit, testOldCommit)
+
+	out, err := UpdateLock([]byte(in), &Archives{URL: server.URL}, testCommits{})
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Docf(`
+		{
+		  "nodes": {
+		    "nixpkgs": {
+		      "locked": {
+		        "lastModified": 1710000000,
+		        "narHash": "%s",
+		        "owner": "NixOS",
+		        "repo": "nixpkgs",
+		        "rev": "%s",
+		        "type": "github"
+		      },
+		      "original": {
+		        "owner": "NixOS",
+		        "ref": "nixos-unstable",
+		        "repo": "nixpkgs",
+		        "type": "github"
+		      }
+		    },
+		    "root": {
+		      "inputs": {
+		        "nixpkgs": "nixpkgs",
+		        "utils": "utils"
+		      }
+		    },
+		    "utils": {
+		      "locked": {
+		        "lastModified": 1700000000,
+		        "narHash": "sha256-BBBB",
+		        "owner": "numtide",
+		        "repo": "flake-utils",
+		        "rev": "%s",
+		        "type": "github"
+		      },
+		      "original": {
+		        "owner": "numtide",
+		        "ref": "v1.0.0",
+		        "repo": "flake-utils",
+		        "type": "github"
+		      }
+		    }
+		  },
+		  "root": "root",
+		  "version": 7
+		}
+	`, SRI(testHash(t, archives, "/NixOS/nixpkgs/archive/"+testNewCommit+".tar.gz")), testNewCommit, testOldCommit), out)
+
+	_, err = UpdateLock([]byte(`{"version": 7}`), &Archives{URL: server.URL}, testCommits{})
+	assert.Equal(t, ErrorNotLock, err)
+}
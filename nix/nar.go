@@ -0,0 +1,208 @@
+package nix
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorEmptyArchive = errors.New("archive has no files")
+)
+
+// node A file, a directory or a symlink of an unpacked archive.
+type node struct {
+	contents   []byte
+	executable bool
+	symlink    string
+	// children is nil for files and symlinks
+	children map[string]*node
+}
+
+func newDir() *node {
+	return &node{children: map[string]*node{}}
+}
+
+// lookup Return the directory of a path, creating the missing directories.
+func (n *node) lookup(parts []string) *node {
+	current := n
+
+	for _, part := range parts {
+		child, ok := current.children[part]
+		if !ok || child.children == nil {
+			child = newDir()
+			current.children[part] = child
+		}
+
+		current = child
+	}
+
+	return current
+}
+
+// unpack Read a tar.gz archive into a tree, dropping the top level
+// directory like Nix does for GitHub archives.
+func unpack(r io.Reader) (*node, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read gzip")
+	}
+
+	root := newDir()
+	reader := tar.NewReader(gz)
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read tar")
+		}
+
+		parts := strings.Split(strings.Trim(path.Clean(header.Name), "/"), "/")
+		if len(parts) < 2 {
+			continue
+		}
+
+		parts = parts[1:]
+		dir := root.lookup(parts[0 : len(parts)-1])
+		name := parts[len(parts)-1]
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			dir.lookup([]string{name})
+		case tar.TypeSymlink:
+			dir.children[name] = &node{symlink: header.Linkname}
+		case tar.TypeReg, tar.TypeRegA:
+			contents, err := ioutil.ReadAll(reader)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot read %s", header.Name)
+			}
+
+			dir.children[name] = &node{
+				contents:   contents,
+				executable: header.Mode&0100 != 0,
+			}
+		}
+	}
+
+	if len(root.children) == 0 {
+		return nil, ErrorEmptyArchive
+	}
+
+	return root, nil
+}
+
+// narString Write a string of the NAR format, which is its length and its
+// bytes padded to 8 bytes.
+func narString(w *bytes.Buffer, s string) {
+	binary.Write(w, binary.LittleEndian, uint64(len(s)))
+	w.WriteString(s)
+
+	if pad := len(s) % 8; pad != 0 {
+		w.Write(make([]byte, 8-pad))
+	}
+}
+
+func (n *node) nar(w *bytes.Buffer) {
+	narString(w, "(")
+	narString(w, "type")
+
+	switch {
+	case n.children != nil:
+		narString(w, "directory")
+
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			narString(w, "entry")
+			narString(w, "(")
+			narString(w, "name")
+			narString(w, name)
+			narString(w, "node")
+			n.children[name].nar(w)
+			narString(w, ")")
+		}
+	case n.symlink != "":
+		narString(w, "symlink")
+		narString(w, "target")
+		narString(w, n.symlink)
+	default:
+		narString(w, "regular")
+
+		if n.executable {
+			narString(w, "executable")
+			narString(w, "")
+		}
+
+		narString(w, "contents")
+		narString(w, string(n.contents))
+	}
+
+	narString(w, ")")
+}
+
+// NarHash Return the sha256 of the NAR serialisation of an unpacked
+// tar.gz archive, which is the `hash` of `fetchFromGitHub` and the
+// `narHash` of `flake.lock`.
+func NarHash(r io.Reader) ([]byte, error) {
+	root, err := unpack(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var w bytes.Buffer
+
+	narString(&w, "nix-archive-1")
+	root.nar(&w)
+
+	sum := sha256.Sum256(w.Bytes())
+
+	return sum[:], nil
+}
+
+// SRI Format a sha256 as a subresource integrity hash, like `sha256-...`.
+func SRI(sum []byte) string {
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+const base32Alphabet = "0123456789abcdfghijklmnpqrsvwxyz"
+
+// Base32 Format a hash in the base32 encoding of Nix, used by the older
+// `sha256 = "..."` attributes.
+func Base32(sum []byte) string {
+	size := (len(sum)*8-1)/5 + 1
+	ret := make([]byte, 0, size)
+
+	for n := size - 1; n >= 0; n-- {
+		b := uint(n * 5)
+		i := b / 8
+		j := b % 8
+
+		c := uint(sum[i]) >> j
+		if int(i) < len(sum)-1 {
+			c |= uint(sum[i+1]) << (8 - j)
+		}
+
+		ret = append(ret, base32Alphabet[c&0x1f])
+	}
+
+	return string(ret)
+}
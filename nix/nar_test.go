@@ -0,0 +1,97 @@
+package nix
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFile A file of a test archive, a directory if the name ends with `/`
+// and a symlink if link is set.
+type testFile struct {
+	name     string
+	contents string
+	mode     int64
+	link     string
+}
+
+// testArchive Create a tar.gz archive like the GitHub source archives.
+func testArchive(t *testing.T, files []testFile) []byte {
+	var out bytes.Buffer
+
+	gz := gzip.NewWriter(&out)
+	w := tar.NewWriter(gz)
+
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: file.mode, Size: int64(len(file.contents)), Typeflag: tar.TypeReg}
+
+		switch {
+		case file.link != "":
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = file.link
+		case file.name[len(file.name)-1] == '/':
+			header.Typeflag = tar.TypeDir
+		}
+
+		assert.Nil(t, w.WriteHeader(header))
+
+		_, err := w.Write([]byte(file.contents))
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, w.Close())
+	assert.Nil(t, gz.Close())
+
+	return out.Bytes()
+}
+
+func TestNarString(t *testing.T) {
+	var w bytes.Buffer
+
+	narString(&w, "(")
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0, '(', 0, 0, 0, 0, 0, 0, 0}, w.Bytes())
+}
+
+func TestNarHash(t *testing.T) {
+	archive := testArchive(t, []testFile{
+		{name: "repo-1.0.0/", mode: 0755},
+		{name: "repo-1.0.0/run.sh", contents: "#!/bin/sh\n", mode: 0755},
+		{name: "repo-1.0.0/README", contents: "hello\n", mode: 0644},
+		{name: "repo-1.0.0/lib/a.txt", contents: "a", mode: 0644},
+		{name: "repo-1.0.0/link", link: "README"},
+	})
+
+	var expected bytes.Buffer
+	for _, s := range []string{
+		"nix-archive-1", "(", "type", "directory",
+		"entry", "(", "name", "README", "node", "(", "type", "regular", "contents", "hello\n", ")", ")",
+		"entry", "(", "name", "lib", "node", "(", "type", "directory",
+		"entry", "(", "name", "a.txt", "node", "(", "type", "regular", "contents", "a", ")", ")",
+		")", ")",
+		"entry", "(", "name", "link", "node", "(", "type", "symlink", "target", "README", ")", ")",
+		"entry", "(", "name", "run.sh", "node", "(", "type", "regular", "executable", "", "contents", "#!/bin/sh\n", ")", ")",
+		")",
+	} {
+		narString(&expected, s)
+	}
+
+	sum := sha256.Sum256(expected.Bytes())
+
+	out, err := NarHash(bytes.NewReader(archive))
+	assert.Nil(t, err)
+	assert.Equal(t, sum[:], out)
+
+	_, err = NarHash(bytes.NewReader(testArchive(t, []testFile{{name: "repo-1.0.0/", mode: 0755}})))
+	assert.Equal(t, ErrorEmptyArchive, err)
+}
+
+func TestBase32(t *testing.T) {
+	sum := sha256.Sum256([]byte{})
+
+	assert.Equal(t, "0mdqa9w1p6cmli6976v4wi0sw9r4p5prkj7lzfd1877wk11c9c73", Base32(sum[:]))
+	assert.Equal(t, "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", SRI(sum[:]))
+}
@@ -0,0 +1,203 @@
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoDependencies = errors.New("no dependencies found")
+)
+
+// sections The objects of `package.json` with dependencies.
+var sections = map[string]bool{
+	"dependencies":         true,
+	"devDependencies":      true,
+	"peerDependencies":     true,
+	"optionalDependencies": true,
+}
+
+// bumpable The range operators that are updated. Upper bounds and complex
+// ranges like `1.x` or `>=1 <2` are left as they are.
+var bumpable = map[string]bool{
+	"":   true,
+	"=":  true,
+	"^":  true,
+	"~":  true,
+	">=": true,
+}
+
+var githubRe = regexp.MustCompile(`^(github:|git\+https://github\.com/|https://github\.com/|git://github\.com/)?([\w.-]+)/([\w.-]+?)(\.git)?#(.+)$`)
+
+// dependency A dependency of `package.json` and the position of its spec, so
+// that it can be replaced without touching the layout of the file.
+type dependency struct {
+	name  string
+	spec  string
+	start int
+	end   int
+}
+
+// members Decode the members of a JSON object and return their values and
+// their positions relative to the object.
+func members(object []byte, each func(key string, value json.RawMessage, start, end int)) error {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('{') {
+		return errors.New("not a json object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		var value json.RawMessage
+
+		err = decoder.Decode(&value)
+		if err != nil {
+			return err
+		}
+
+		end := int(decoder.InputOffset())
+		each(token.(string), value, end-len(value), end)
+	}
+
+	return nil
+}
+
+// dependencies Find the dependencies of all the sections of a `package.json`.
+func dependencies(bytesIn []byte) ([]dependency, error) {
+	var ret []dependency
+
+	found := false
+
+	err := members(bytesIn, func(key string, value json.RawMessage, start, end int) {
+		if !sections[key] || !bytes.HasPrefix(value, []byte("{")) {
+			return
+		}
+
+		found = true
+
+		err := members(value, func(name string, spec json.RawMessage, specStart, specEnd int) {
+			var text string
+			if json.Unmarshal(spec, &text) != nil {
+				return
+			}
+
+			ret = append(ret, dependency{
+				name:  name,
+				spec:  text,
+				start: start + specStart,
+				end:   start + specEnd,
+			})
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":     err,
+				"section": key,
+			}).Debug("Cannot parse dependencies")
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse package.json")
+	}
+
+	if !found {
+		return nil, ErrorNoDependencies
+	}
+
+	return ret, nil
+}
+
+// updateRange Update a version range like `^1.2.3` to the latest version of
+// the package, keeping its operator.
+func updateRange(name, spec string, registry *Registry) string {
+	constraint, err := versions.ParseConstraint(spec)
+	if err != nil || !bumpable[constraint.Operator] {
+		log.WithFields(log.Fields{
+			"package": name,
+			"spec":    spec,
+		}).Debug("Spec is not updated")
+
+		return spec
+	}
+
+	latest, err := registry.Latest(name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":     err,
+			"package": name,
+		}).Error("Cannot find the latest version")
+
+		return spec
+	}
+
+	if !versions.Less(constraint.Version, latest) {
+		return spec
+	}
+
+	return constraint.Bump(latest)
+}
+
+// updateSpec Update the spec of a dependency, which can be a version range,
+// an `npm:name@range` alias or a GitHub `owner/repo#tag`.
+func updateSpec(name, spec string, registry *Registry, resolve gh.Resolver) string {
+	if strings.HasPrefix(spec, "npm:") {
+		alias := strings.TrimPrefix(spec, "npm:")
+
+		at := strings.LastIndex(alias, "@")
+		if at <= 0 {
+			return spec
+		}
+
+		return "npm:" + alias[0:at+1] + updateRange(alias[0:at], alias[at+1:], registry)
+	}
+
+	if found := githubRe.FindStringSubmatchIndex(spec); found != nil {
+		owner := spec[found[4]:found[5]]
+		repo := spec[found[6]:found[7]]
+
+		return spec[0:found[10]] + resolve.Next(owner, repo, spec[found[10]:found[11]])
+	}
+
+	return updateRange(name, spec, registry)
+}
+
+// Update Update the `dependencies`, `devDependencies`, `peerDependencies`
+// and `optionalDependencies` of a `package.json`, keeping the layout of
+// the file.
+func Update(bytesIn []byte, registry *Registry, resolve gh.Resolver) (string, error) {
+	deps, err := dependencies(bytesIn)
+	if err != nil {
+		return "", err
+	}
+
+	ret := string(bytesIn)
+
+	for i := len(deps) - 1; i >= 0; i-- {
+		dep := deps[i]
+
+		updated := updateSpec(dep.name, dep.spec, registry, resolve)
+		if updated == dep.spec {
+			continue
+		}
+
+		ret = ret[0:dep.start] + `"` + updated + `"` + ret[dep.end:]
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,114 @@
+package npm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPackages = map[string]map[string]interface{}{
+	"react": {
+		"dist-tags": map[string]string{"latest": "18.2.0", "next": "19.0.0-rc.0"},
+	},
+	"@types/node": {
+		"dist-tags": map[string]string{"latest": "20.11.30"},
+	},
+	"lodash": {
+		"dist-tags": map[string]string{"latest": "4.17.21"},
+	},
+	"typescript": {
+		"versions": map[string]interface{}{"5.3.3": nil, "5.4.3": nil, "5.5.0-beta": nil},
+	},
+}
+
+// testRegistry A local stand-in of the npm registry.
+func testRegistry(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pkg, ok := testPackages[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		json.NewEncoder(w).Encode(pkg)
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"owner/tool": "v1.4.0",
+})
+
+func TestUpdate(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "sections and specs",
+			in: heredoc.Doc(`
+				{
+				  "name": "app",
+				  "version": "1.0.0",
+				  "dependencies": {
+				    "react": "^17.0.2",
+				    "lodash": "4.17.20",
+				    "tool": "github:owner/tool#v1.2.0",
+				    "other": "owner/tool#0123abc",
+				    "legacy-react": "npm:react@~17.0.2",
+				    "missing": "^1.0.0",
+				    "local": "file:../local"
+				  },
+				  "devDependencies": {"@types/node": "~20.10", "typescript": ">=5.3.3"},
+				  "peerDependencies": {
+				    "react": ">=16 <19",
+				    "lodash": "*"
+				  }
+				}
+			`),
+			out: heredoc.Doc(`
+				{
+				  "name": "app",
+				  "version": "1.0.0",
+				  "dependencies": {
+				    "react": "^18.2.0",
+				    "lodash": "4.17.21",
+				    "tool": "github:owner/tool#v1.4.0",
+				    "other": "owner/tool#0123abc",
+				    "legacy-react": "npm:react@~18.2.0",
+				    "missing": "^1.0.0",
+				    "local": "file:../local"
+				  },
+				  "devDependencies": {"@types/node": "~20.11", "typescript": ">=5.4.3"},
+				  "peerDependencies": {
+				    "react": ">=16 <19",
+				    "lodash": "*"
+				  }
+				}
+			`),
+		},
+		{
+			name: "no dependencies",
+			in:   `{"name": "app"}`,
+			err:  ErrorNoDependencies,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := Update([]byte(test.in), &Registry{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
@@ -0,0 +1,111 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+const DefaultRegistry = "https://registry.npmjs.org"
+
+var (
+	ErrorPackageNotFound = errors.New("package not found in the registry")
+)
+
+// Registry A client for the npm registry API.
+type Registry struct {
+	// URL overrides the npm registry, for example to use a local registry.
+	URL    string
+	Client *http.Client
+	latest map[string]string
+}
+
+func (r *Registry) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+
+	return r.Client
+}
+
+func (r *Registry) base() string {
+	if r.URL == "" {
+		return DefaultRegistry
+	}
+
+	return strings.TrimSuffix(r.URL, "/")
+}
+
+// Latest Find the latest version of a package, which is its `latest`
+// dist-tag or, without one, its greatest version that is not a
+// pre-release.
+func (r *Registry) Latest(name string) (string, error) {
+	if cached, ok := r.latest[name]; ok {
+		return cached, nil
+	}
+
+	// scoped packages are requested as `@scope%2fname`
+	address := fmt.Sprintf("%s/%s", r.base(), strings.Replace(name, "/", "%2f", 1))
+
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot create request for %s", address)
+	}
+
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrorPackageNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	var pkg struct {
+		DistTags map[string]string          `json:"dist-tags"`
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+
+	err = json.Unmarshal(body, &pkg)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot parse %s", address)
+	}
+
+	latest, ok := pkg.DistTags["latest"]
+	if !ok {
+		var available []string
+		for version := range pkg.Versions {
+			available = append(available, version)
+		}
+
+		latest, err = versions.Latest(available)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if r.latest == nil {
+		r.latest = map[string]string{}
+	}
+
+	r.latest[name] = latest
+
+	return latest, nil
+}
@@ -0,0 +1,145 @@
+package python
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultIndex = "https://pypi.org"
+
+var (
+	ErrorPackageNotFound = errors.New("package not found in the index")
+)
+
+// Index A client for the JSON API of PyPI, falling back to the JSON simple
+// API of PEP 691 for indexes that do not have the PyPI one.
+type Index struct {
+	// URL overrides PyPI, for example to use a local index.
+	URL      string
+	Client   *http.Client
+	versions map[string][]string
+}
+
+var normaliseRe = regexp.MustCompile(`[-_.]+`)
+
+// Normalise Normalise a package name as PEP 503 does, so that
+// `Foo.Bar_baz` becomes `foo-bar-baz`.
+func Normalise(name string) string {
+	return strings.ToLower(normaliseRe.ReplaceAllString(name, "-"))
+}
+
+func (i *Index) client() *http.Client {
+	if i.Client == nil {
+		return http.DefaultClient
+	}
+
+	return i.Client
+}
+
+func (i *Index) base() string {
+	if i.URL == "" {
+		return DefaultIndex
+	}
+
+	return strings.TrimSuffix(i.URL, "/")
+}
+
+func (i *Index) get(address, accept string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create request for %s", address)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := i.client().Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrorPackageNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// Versions List the versions of a package that have files that are not
+// yanked.
+func (i *Index) Versions(name string) ([]string, error) {
+	name = Normalise(name)
+
+	if cached, ok := i.versions[name]; ok {
+		return cached, nil
+	}
+
+	var ret []string
+
+	var project struct {
+		Releases map[string][]struct {
+			Yanked bool `json:"yanked"`
+		} `json:"releases"`
+	}
+
+	err := i.get(fmt.Sprintf("%s/pypi/%s/json", i.base(), name), "", &project)
+	if err == nil {
+		for version, files := range project.Releases {
+			for _, file := range files {
+				if !file.Yanked {
+					ret = append(ret, version)
+
+					break
+				}
+			}
+		}
+	} else {
+		ret, err = i.simple(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if i.versions == nil {
+		i.versions = map[string][]string{}
+	}
+
+	i.versions[name] = ret
+
+	return ret, nil
+}
+
+// simple List the versions of a package with the JSON simple API.
+func (i *Index) simple(name string) ([]string, error) {
+	var project struct {
+		Versions []string `json:"versions"`
+	}
+
+	err := i.get(
+		fmt.Sprintf("%s/simple/%s/", i.base(), name),
+		"application/vnd.pypi.simple.v1+json",
+		&project,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.Versions, nil
+}
@@ -0,0 +1,104 @@
+package python
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testReleases The releases of the local index, with the yanked ones.
+var testReleases = map[string]map[string]bool{
+	"requests": {"2.30.0": false, "2.31.0": false, "2.32.0": true, "3.0.0rc1": false},
+	"urllib3":  {"1.26.18": false, "2.2.1": false},
+	"django":   {"4.2.11": false, "5.0.3": false},
+	"black":    {"23.12.1": false, "24.2.0": false},
+}
+
+// testSimple Packages that are only available with the simple API.
+var testSimple = map[string][]string{
+	"private-pkg": {"0.1.0", "0.2.0"},
+}
+
+// testIndex A local stand-in of PyPI.
+func testIndex(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+		switch {
+		case len(parts) == 3 && parts[0] == "pypi" && parts[2] == "json":
+			releases, ok := testReleases[parts[1]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			ret := map[string][]map[string]bool{}
+			for version, yanked := range releases {
+				ret[version] = []map[string]bool{{"yanked": yanked}}
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"releases": ret})
+		case len(parts) == 2 && parts[0] == "simple":
+			versions, ok := testSimple[parts[1]]
+			if !ok || r.Header.Get("Accept") != "application/vnd.pypi.simple.v1+json" {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"versions": versions})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"psf/black":      "24.2.0",
+	"owner/internal": "v1.4.0",
+})
+
+func TestVersions(t *testing.T) {
+	server := testIndex(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  []string
+		err  error
+	}{
+		{
+			name: "yanked releases are skipped",
+			in:   "Requests",
+			out:  []string{"2.30.0", "2.31.0", "3.0.0rc1"},
+		},
+		{
+			name: "simple API",
+			in:   "private_pkg",
+			out:  []string{"0.1.0", "0.2.0"},
+		},
+		{
+			name: "missing package",
+			in:   "missing",
+			err:  ErrorPackageNotFound,
+		},
+	}
+
+	index := Index{URL: server.URL}
+
+	for _, test := range cases {
+		versions, err := index.Versions(test.in)
+		sort.Strings(versions)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, versions, test.name)
+	}
+}
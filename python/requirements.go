@@ -0,0 +1,175 @@
+package python
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+)
+
+// bumpable The operators of the specifiers that are updated. Upper bounds
+// and exclusions like `<3` and `!=2.0` are left as they are.
+var bumpable = map[string]bool{
+	"":   true,
+	"=":  true,
+	"==": true,
+	"~=": true,
+	">=": true,
+	"^":  true,
+	"~":  true,
+}
+
+var (
+	nameRe = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?`)
+	gitRe  = regexp.MustCompile(`git\+https://github\.com/([^/\s]+)/([^@\s#;]+)@([^\s#;&]+)`)
+)
+
+// IsRequirements Check if a file name looks like a pip requirements file,
+// for example `requirements.txt` or `requirements-dev.txt`.
+func IsRequirements(file string) bool {
+	name := filepath.Base(file)
+
+	return strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt")
+}
+
+// updateVersion Update a version specifier like ` >= 2.0 ` to the latest
+// version of the package, keeping its operator and its spaces.
+func updateVersion(name, specifier string, index *Index) string {
+	trimmed := strings.TrimSpace(specifier)
+	if trimmed == "" {
+		return specifier
+	}
+
+	constraint, err := versions.ParseConstraint(trimmed)
+	if err != nil || !bumpable[constraint.Operator] {
+		log.WithFields(log.Fields{
+			"package":   name,
+			"specifier": trimmed,
+		}).Debug("Specifier is not updated")
+
+		return specifier
+	}
+
+	available, err := index.Versions(name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":     err,
+			"package": name,
+		}).Error("Cannot list package versions")
+
+		return specifier
+	}
+
+	latest, err := versions.Latest(available)
+	if err != nil || !versions.Less(constraint.Version, latest) {
+		return specifier
+	}
+
+	return strings.Replace(specifier, trimmed, constraint.Bump(latest), 1)
+}
+
+// updateGitRef Update the `@ref` of a `git+https://github.com/...` URL.
+func updateGitRef(spec string, resolve gh.Resolver) string {
+	found := gitRe.FindStringSubmatchIndex(spec)
+	if found == nil {
+		return spec
+	}
+
+	owner := spec[found[2]:found[3]]
+	repo := strings.TrimSuffix(spec[found[4]:found[5]], ".git")
+	ref := spec[found[6]:found[7]]
+
+	return spec[0:found[6]] + resolve.Next(owner, repo, ref) + spec[found[7]:]
+}
+
+// updateSpec Update a PEP 508 requirement like
+// `requests[socks] >= 2.0; python_version < "3.8"`.
+func updateSpec(spec string, index *Index, resolve gh.Resolver) string {
+	if strings.Contains(spec, "git+") {
+		return updateGitRef(spec, resolve)
+	}
+
+	found := nameRe.FindStringSubmatchIndex(spec)
+	if found == nil {
+		return spec
+	}
+
+	name := spec[found[2]:found[3]]
+	rest := spec[found[1]:]
+
+	if strings.HasPrefix(strings.TrimSpace(rest), "@") {
+		// direct URL reference
+		return spec
+	}
+
+	end := len(rest)
+	if pos := strings.IndexAny(rest, ";#"); pos >= 0 {
+		end = pos
+	}
+
+	return spec[0:found[1]] + updateVersion(name, rest[0:end], index) + rest[end:]
+}
+
+// includeOptions The options of requirements files that include other files.
+var includeOptions = []string{"-r", "--requirement", "-c", "--constraint"}
+
+// Includes Return the files that a requirements file includes with `-r` and
+// `-c`, relative to the directory of the file.
+func Includes(bytesIn []byte) []string {
+	var ret []string
+
+	for _, line := range strings.Split(string(bytesIn), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		for _, option := range includeOptions {
+			switch {
+			case fields[0] == option && len(fields) > 1:
+				ret = append(ret, fields[1])
+			case strings.HasPrefix(fields[0], option+"="):
+				ret = append(ret, strings.TrimPrefix(fields[0], option+"="))
+			}
+		}
+	}
+
+	return ret
+}
+
+// UpdateRequirements Update the versions of a pip requirements file to the
+// latest versions of the index and the `git+https://github.com/...@ref`
+// URLs to the next GitHub release. Requirements with `--hash` options are
+// left as they are, as the hashes would not match.
+func UpdateRequirements(bytesIn []byte, index *Index, resolve gh.Resolver) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	continuation := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		continued := continuation
+		continuation = strings.HasSuffix(trimmed, "\\")
+
+		switch {
+		case continued || continuation:
+			continue
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.Contains(line, "--hash"):
+			log.WithFields(log.Fields{
+				"line": line,
+			}).Debug("Requirement has hashes, leaving it as is")
+		case strings.HasPrefix(trimmed, "-e") || strings.HasPrefix(trimmed, "--editable"):
+			lines[i] = updateGitRef(line, resolve)
+		case strings.HasPrefix(trimmed, "-"):
+			continue
+		default:
+			lines[i] = updateSpec(line, index, resolve)
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
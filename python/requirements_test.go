@@ -0,0 +1,64 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRequirements(t *testing.T) {
+	server := testIndex(t)
+	defer server.Close()
+
+	in := heredoc.Doc(`
+		# pinned
+		-r requirements-base.txt
+		--index-url https://pypi.example.com/simple
+		requests[socks]==2.30.0 ; python_version >= "3.8" # http
+		urllib3 ~= 1.26
+		Django>=4.2.11,<5
+		private_pkg >= 0.1
+		black @ git+https://github.com/psf/black.git@23.12.1
+		-e git+https://github.com/owner/internal.git@v1.2.0#egg=internal
+		git+https://github.com/owner/internal.git@0123abc#egg=internal
+		urllib3==1.26.18 \
+		    --hash=sha256:0000
+		missing==1.0.0
+	`)
+
+	out, err := UpdateRequirements([]byte(in), &Index{URL: server.URL}, testResolve)
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		# pinned
+		-r requirements-base.txt
+		--index-url https://pypi.example.com/simple
+		requests[socks]==2.31.0 ; python_version >= "3.8" # http
+		urllib3 ~= 2.2
+		Django>=4.2.11,<5
+		private_pkg >= 0.2
+		black @ git+https://github.com/psf/black.git@24.2.0
+		-e git+https://github.com/owner/internal.git@v1.4.0#egg=internal
+		git+https://github.com/owner/internal.git@0123abc#egg=internal
+		urllib3==1.26.18 \
+		    --hash=sha256:0000
+		missing==1.0.0
+	`), out)
+}
+
+func TestIncludes(t *testing.T) {
+	in := heredoc.Doc(`
+		-r base.txt
+		--requirement=dev.txt
+		-c constraints.txt
+		requests==2.31.0
+	`)
+
+	assert.Equal(t, []string{"base.txt", "dev.txt", "constraints.txt"}, Includes([]byte(in)))
+}
+
+func TestIsRequirements(t *testing.T) {
+	assert.True(t, IsRequirements("a/requirements-dev.txt"))
+	assert.True(t, IsRequirements("requirements.txt"))
+	assert.False(t, IsRequirements("constraints.txt"))
+}
@@ -0,0 +1,124 @@
+package python
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/tomlvalue"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoDependencies = errors.New("no dependency tables found")
+)
+
+var (
+	tableRe    = regexp.MustCompile(`^\s*\[\[?([^\[\]]+)\]\]?\s*(#.*)?$`)
+	keyRe      = regexp.MustCompile(`^(\s*("[^"]+"|'[^']+'|[A-Za-z0-9._-]+)\s*=\s*)(.*)$`)
+	quotedRe   = regexp.MustCompile(`"([^"\\]*)"|'([^']*)'`)
+	versionRe  = regexp.MustCompile(`\bversion\s*=\s*["']([^"']*)["']`)
+	gitTableRe = regexp.MustCompile(`\bgit\s*=\s*["']https://github\.com/([^/"']+)/([^/"']+?)(\.git)?["']`)
+	tagRe      = regexp.MustCompile(`\b(tag|ref)\s*=\s*["']([^"']*)["']`)
+	poetryRe   = regexp.MustCompile(`^tool\.poetry\.(group\.[^.]+\.)?(dev-)?dependencies$`)
+)
+
+// keyTable Check if the table has a `name = "version"` entry per dependency,
+// like the Poetry tables of `pyproject.toml` and the tables of `Pipfile`.
+func keyTable(table string) bool {
+	return poetryRe.MatchString(table) || table == "packages" || table == "dev-packages"
+}
+
+// arrayKey Check if the key holds an array of PEP 508 requirements, like
+// the PEP 621 `[project]` dependencies.
+func arrayKey(table, key string) bool {
+	return (table == "project" && key == "dependencies") || table == "project.optional-dependencies"
+}
+
+// closesArray Check if the line closes an array, ignoring the brackets of
+// the quoted strings like `requests[socks]`.
+func closesArray(line string) bool {
+	return strings.Contains(quotedRe.ReplaceAllString(line, `""`), "]")
+}
+
+// updateQuoted Update the requirements of the quoted strings of a line.
+func updateQuoted(line string, index *Index, resolve gh.Resolver) string {
+	return quotedRe.ReplaceAllStringFunc(line, func(quoted string) string {
+		quote := quoted[0:1]
+		spec := quoted[1 : len(quoted)-1]
+
+		return quote + updateSpec(spec, index, resolve) + quote
+	})
+}
+
+// updateValue Update the value of a dependency, that is either a version
+// string or an inline table with a `version` or a GitHub `git` and `tag`.
+func updateValue(name, value string, index *Index, resolve gh.Resolver) string {
+	trimmed := strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(trimmed, `"`), strings.HasPrefix(trimmed, "'"):
+		return tomlvalue.ReplaceSubmatch(quotedRe, value, 0, func(quoted string) string {
+			return quoted[0:1] + updateVersion(name, quoted[1:len(quoted)-1], index) + quoted[len(quoted)-1:]
+		})
+	case !strings.HasPrefix(trimmed, "{"):
+		return value
+	}
+
+	if git := gitTableRe.FindStringSubmatch(value); git != nil {
+		return tomlvalue.ReplaceSubmatch(tagRe, value, 2, func(tag string) string {
+			return resolve.Next(git[1], git[2], tag)
+		})
+	}
+
+	return tomlvalue.ReplaceSubmatch(versionRe, value, 1, func(version string) string {
+		return updateVersion(name, version, index)
+	})
+}
+
+// UpdateTOML Update the dependencies of a `pyproject.toml`, both the PEP 621
+// `[project]` and the Poetry tables, or of a `Pipfile`.
+func UpdateTOML(bytesIn []byte, index *Index, resolve gh.Resolver) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	table := ""
+	inArray := false
+	found := false
+
+	for i, line := range lines {
+		if inArray {
+			lines[i] = updateQuoted(line, index, resolve)
+			inArray = !closesArray(line)
+
+			continue
+		}
+
+		if match := tableRe.FindStringSubmatch(line); match != nil {
+			table = strings.TrimSpace(match[1])
+
+			continue
+		}
+
+		match := keyRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		key := strings.Trim(match[2], `"'`)
+
+		switch {
+		case arrayKey(table, key) && strings.HasPrefix(strings.TrimSpace(match[3]), "["):
+			found = true
+			lines[i] = match[1] + updateQuoted(match[3], index, resolve)
+			inArray = !closesArray(match[3])
+		case keyTable(table) && key != "python":
+			found = true
+			lines[i] = match[1] + updateValue(key, match[3], index, resolve)
+		}
+	}
+
+	if !found {
+		return "", ErrorNoDependencies
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
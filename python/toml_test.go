@@ -0,0 +1,115 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateTOML(t *testing.T) {
+	server := testIndex(t)
+	defer server.Close()
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "PEP 621",
+			in: heredoc.Doc(`
+				[project]
+				name = "app"
+				version = "1.0.0"
+				dependencies = [
+				    "requests[socks]>=2.30",
+				    "django==4.2.11; python_version >= '3.10'",
+				]
+
+				[project.optional-dependencies]
+				dev = ["black==23.12.1", "urllib3<2"]
+			`),
+			out: heredoc.Doc(`
+				[project]
+				name = "app"
+				version = "1.0.0"
+				dependencies = [
+				    "requests[socks]>=2.31",
+				    "django==5.0.3; python_version >= '3.10'",
+				]
+
+				[project.optional-dependencies]
+				dev = ["black==24.2.0", "urllib3<2"]
+			`),
+		},
+		{
+			name: "poetry",
+			in: heredoc.Doc(`
+				[tool.poetry.dependencies]
+				python = "^3.10"
+				requests = "^2.30"
+				django = { version = "~4.2.11", extras = ["argon2"] }
+				internal = { git = "https://github.com/owner/internal.git", tag = "v1.2.0" }
+
+				[tool.poetry.group.dev.dependencies]
+				black = "23.12.1" # formatter
+			`),
+			out: heredoc.Doc(`
+				[tool.poetry.dependencies]
+				python = "^3.10"
+				requests = "^2.31"
+				django = { version = "~5.0.3", extras = ["argon2"] }
+				internal = { git = "https://github.com/owner/internal.git", tag = "v1.4.0" }
+
+				[tool.poetry.group.dev.dependencies]
+				black = "24.2.0" # formatter
+			`),
+		},
+		{
+			name: "Pipfile",
+			in: heredoc.Doc(`
+				[[source]]
+				url = "https://pypi.org/simple"
+				verify_ssl = true
+
+				[packages]
+				requests = "==2.30.0"
+				urllib3 = "*"
+
+				[dev-packages]
+				black = {version = ">=23.12.1"}
+
+				[requires]
+				python_version = "3.11"
+			`),
+			out: heredoc.Doc(`
+				[[source]]
+				url = "https://pypi.org/simple"
+				verify_ssl = true
+
+				[packages]
+				requests = "==2.31.0"
+				urllib3 = "*"
+
+				[dev-packages]
+				black = {version = ">=24.2.0"}
+
+				[requires]
+				python_version = "3.11"
+			`),
+		},
+		{
+			name: "no dependencies",
+			in:   "[tool.black]\nline-length = 100\n",
+			err:  ErrorNoDependencies,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := UpdateTOML([]byte(test.in), &Index{URL: server.URL}, testResolve)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
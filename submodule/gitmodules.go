@@ -0,0 +1,65 @@
+package submodule
+
+import (
+	"regexp"
+	"strings"
+)
+
+const File = ".gitmodules"
+
+var (
+	sectionRe = regexp.MustCompile(`^\s*\[submodule\s+"([^"]+)"\]`)
+	optionRe  = regexp.MustCompile(`^\s*([A-Za-z]+)\s*=\s*(.*?)\s*$`)
+	githubRe  = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(\.git)?/?$`)
+)
+
+// Submodule A submodule of a `.gitmodules` file.
+type Submodule struct {
+	Name   string
+	Path   string
+	URL    string
+	Branch string
+}
+
+// Github Return the owner and the repository of a submodule hosted on
+// GitHub.
+func (s *Submodule) Github() (string, string, bool) {
+	found := githubRe.FindStringSubmatch(s.URL)
+	if found == nil {
+		return "", "", false
+	}
+
+	return found[1], found[2], true
+}
+
+// Parse Parse the submodules of a `.gitmodules` file.
+func Parse(bytesIn []byte) []Submodule {
+	var ret []Submodule
+
+	for _, line := range strings.Split(string(bytesIn), "\n") {
+		if found := sectionRe.FindStringSubmatch(line); found != nil {
+			ret = append(ret, Submodule{Name: found[1]})
+
+			continue
+		}
+
+		found := optionRe.FindStringSubmatch(line)
+		if found == nil || len(ret) == 0 {
+			continue
+		}
+
+		current := &ret[len(ret)-1]
+		value := strings.Trim(found[2], `"`)
+
+		switch strings.ToLower(found[1]) {
+		case "path":
+			current.Path = value
+		case "url":
+			current.URL = value
+		case "branch":
+			current.Branch = value
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,111 @@
+package submodule
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNotSubmodule = errors.New("path is not a submodule in the git index")
+)
+
+// Update A submodule that is behind the latest tag of its repository.
+type Update struct {
+	Submodule
+	Current string
+	Tag     string
+	Commit  string
+}
+
+func (u Update) String() string {
+	return fmt.Sprintf("%s: %s -> %s (%s)", u.Path, u.Current, u.Tag, u.Commit)
+}
+
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), out)
+	}
+
+	return string(out), nil
+}
+
+// Commit Return the commit of a submodule in the git index.
+func Commit(dir, path string) (string, error) {
+	out, err := git(dir, "ls-files", "--stage", "--", path)
+	if err != nil {
+		return "", err
+	}
+
+	// 160000 <commit> 0	<path>
+	fields := strings.Fields(out)
+	if len(fields) < 2 || fields[0] != "160000" {
+		return "", ErrorNotSubmodule
+	}
+
+	return fields[1], nil
+}
+
+// Move Point a submodule to a commit in the git index, same as checking out
+// the commit in the submodule and staging it.
+func Move(dir, path, commit string) error {
+	_, err := git(dir, "update-index", "--cacheinfo", "160000,"+commit+","+path)
+
+	return err
+}
+
+// Updates Find the submodules of the repository in dir that are behind the
+// latest tag of their repository. Submodules that track a branch with
+// `branch =` are left to `git submodule update --remote`.
+func Updates(dir string, submodules []Submodule, repos gh.Repositories) []Update {
+	var ret []Update
+
+	for _, submodule := range submodules {
+		if submodule.Branch != "" {
+			log.WithFields(log.Fields{
+				"path":   submodule.Path,
+				"branch": submodule.Branch,
+			}).Debug("Submodule tracks a branch")
+
+			continue
+		}
+
+		owner, repo, ok := submodule.Github()
+		if !ok {
+			log.WithFields(log.Fields{
+				"url": submodule.URL,
+			}).Debug("Submodule is not hosted on GitHub")
+
+			continue
+		}
+
+		current, err := Commit(dir, submodule.Path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":  err,
+				"path": submodule.Path,
+			}).Error("Cannot find the submodule commit")
+
+			continue
+		}
+
+		tag, commit, moved := gh.NextCommit(repos, owner, repo, current)
+		if !moved {
+			continue
+		}
+
+		ret = append(ret, Update{
+			Submodule: submodule,
+			Current:   current,
+			Tag:       tag,
+			Commit:    commit,
+		})
+	}
+
+	return ret
+}
@@ -0,0 +1,76 @@
+package submodule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oldCommit    = "1111111111111111111111111111111111111111"
+	latestCommit = "2222222222222222222222222222222222222222"
+	newerCommit  = "3333333333333333333333333333333333333333"
+)
+
+// testRepos A stand-in of the GitHub API where every repository has its
+// latest tag at latestCommit, and newerCommit is ahead of it.
+type testRepos struct{}
+
+func (testRepos) LatestTag(owner, repo string) (string, string, error) {
+	return "v1.2.0", latestCommit, nil
+}
+
+func (testRepos) Ahead(owner, repo, base, head string) (bool, error) {
+	return base == oldCommit, nil
+}
+
+func TestParse(t *testing.T) {
+	in := heredoc.Doc(`
+		[submodule "lib"]
+			path = vendor/lib
+			url = https://github.com/owner/lib.git
+		[submodule "docs"]
+			path = docs
+			url = git@github.com:owner/docs
+			branch = main
+	`)
+
+	assert.Equal(t, []Submodule{
+		{Name: "lib", Path: "vendor/lib", URL: "https://github.com/owner/lib.git"},
+		{Name: "docs", Path: "docs", URL: "git@github.com:owner/docs", Branch: "main"},
+	}, Parse([]byte(in)))
+}
+
+func TestUpdates(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := git(dir, "init", "-q")
+	assert.Nil(t, err)
+
+	for path, commit := range map[string]string{"vendor/lib": oldCommit, "vendor/newer": newerCommit, "docs": oldCommit} {
+		_, err = git(dir, "update-index", "--add", "--cacheinfo", "160000,"+commit+","+path)
+		assert.Nil(t, err)
+	}
+
+	submodules := []Submodule{
+		{Name: "lib", Path: "vendor/lib", URL: "https://github.com/owner/lib.git"},
+		{Name: "newer", Path: "vendor/newer", URL: "https://github.com/owner/newer.git"},
+		{Name: "docs", Path: "docs", URL: "git@github.com:owner/docs", Branch: "main"},
+		{Name: "other", Path: "other", URL: "https://gitlab.com/owner/other.git"},
+	}
+
+	updates := Updates(dir, submodules, testRepos{})
+	assert.Equal(t, []Update{
+		{Submodule: submodules[0], Current: oldCommit, Tag: "v1.2.0", Commit: latestCommit},
+	}, updates)
+	assert.True(t, strings.HasPrefix(updates[0].String(), "vendor/lib: "+oldCommit+" -> v1.2.0"))
+
+	err = Move(dir, updates[0].Path, updates[0].Commit)
+	assert.Nil(t, err)
+
+	commit, err := Commit(dir, "vendor/lib")
+	assert.Nil(t, err)
+	assert.Equal(t, latestCommit, commit)
+}
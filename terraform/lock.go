@@ -0,0 +1,101 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+)
+
+const LockFile = ".terraform.lock.hcl"
+
+var lockProviderRe = regexp.MustCompile(`^provider\s+"([^"]+)"\s*{`)
+
+// UpdateLock Rewrite the `provider` blocks of a `.terraform.lock.hcl` file
+// for the given providers with their new version, constraints and hashes.
+// Providers missing from the lock file are appended to it.
+func UpdateLock(bytesIn []byte, providers []Provider, registry *Registry) (string, error) {
+	blocks := map[string]string{}
+
+	for _, provider := range providers {
+		address, err := Address(provider.Source)
+		if err != nil {
+			return "", err
+		}
+
+		hashes, err := registry.Hashes(provider.Source, provider.Version)
+		if err != nil {
+			return "", err
+		}
+
+		blocks[address] = lockBlock(address, provider, hashes)
+	}
+
+	var ret []string
+	var skip bool
+
+	for _, line := range strings.Split(string(bytesIn), "\n") {
+		if skip {
+			if line == "}" {
+				skip = false
+			}
+
+			continue
+		}
+
+		match := lockProviderRe.FindStringSubmatch(line)
+		if len(match) > 0 {
+			if block, ok := blocks[match[1]]; ok {
+				log.WithFields(log.Fields{
+					"provider": match[1],
+				}).Debug("Updating lock file provider")
+
+				ret = append(ret, block)
+				delete(blocks, match[1])
+				skip = true
+
+				continue
+			}
+		}
+
+		ret = append(ret, line)
+	}
+
+	for _, provider := range providers {
+		address, _ := Address(provider.Source)
+
+		block, ok := blocks[address]
+		if !ok {
+			continue
+		}
+
+		if len(ret) > 0 && ret[len(ret)-1] == "" {
+			ret = ret[0 : len(ret)-1]
+		}
+
+		ret = append(ret, "", block, "")
+	}
+
+	return strings.Join(ret, "\n"), nil
+}
+
+func lockBlock(address string, provider Provider, hashes []string) string {
+	lines := []string{
+		fmt.Sprintf(`provider "%s" {`, address),
+		fmt.Sprintf(`  version     = "%s"`, provider.Version),
+	}
+
+	if provider.Constraints != "" {
+		lines = append(lines, fmt.Sprintf(`  constraints = "%s"`, strings.TrimSpace(provider.Constraints)))
+	}
+
+	lines = append(lines, "  hashes = [")
+	for _, hash := range hashes {
+		lines = append(lines, fmt.Sprintf(`    "%s",`, hash))
+	}
+
+	lines = append(lines, "  ]", "}")
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,98 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLock(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "existing provider block",
+			in: heredoc.Doc(`
+				# This file is maintained automatically by "terraform init".
+				# Manual edits may be lost in future updates.
+
+				provider "registry.terraform.io/hashicorp/null" {
+				  version     = "3.1.0"
+				  constraints = "~> 3.1"
+				  hashes = [
+				    "h1:old",
+				    "zh:old",
+				  ]
+				}
+
+				provider "registry.terraform.io/hashicorp/random" {
+				  version = "3.0.0"
+				  hashes = [
+				    "h1:random",
+				  ]
+				}
+			`),
+			out: heredoc.Doc(`
+				# This file is maintained automatically by "terraform init".
+				# Manual edits may be lost in future updates.
+
+				provider "registry.terraform.io/hashicorp/null" {
+				  version     = "3.2.1"
+				  constraints = "~> 3.2"
+				  hashes = [
+				    "h1:Qkg7skmGGGnA2Ncnmv0LtD5wnvObdwpe2kFckYx523Y=",
+				    "h1:cnynjkF+Iw16E3dZ2e84eHXAieRfnFkKzkdDqsp9EZ0=",
+				    "zh:55252aaa39c6590cbcda51b4afe3d8cbfc8754b8c50af5196f02805ed85d160a",
+				    "zh:5d12d897590048e81f05552a6459ffdfcfc4661a4716e7d89d8008c63f7acd4c",
+				  ]
+				}
+
+				provider "registry.terraform.io/hashicorp/random" {
+				  version = "3.0.0"
+				  hashes = [
+				    "h1:random",
+				  ]
+				}
+			`),
+		},
+		{
+			name: "provider missing from the lock file",
+			in: heredoc.Doc(`
+				# This file is maintained automatically by "terraform init".
+				# Manual edits may be lost in future updates.
+			`),
+			out: heredoc.Doc(`
+				# This file is maintained automatically by "terraform init".
+				# Manual edits may be lost in future updates.
+
+				provider "registry.terraform.io/hashicorp/null" {
+				  version     = "3.2.1"
+				  constraints = "~> 3.2"
+				  hashes = [
+				    "h1:Qkg7skmGGGnA2Ncnmv0LtD5wnvObdwpe2kFckYx523Y=",
+				    "h1:cnynjkF+Iw16E3dZ2e84eHXAieRfnFkKzkdDqsp9EZ0=",
+				    "zh:55252aaa39c6590cbcda51b4afe3d8cbfc8754b8c50af5196f02805ed85d160a",
+				    "zh:5d12d897590048e81f05552a6459ffdfcfc4661a4716e7d89d8008c63f7acd4c",
+				  ]
+				}
+			`),
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL, Platforms: []string{"linux_amd64", "darwin_arm64"}}
+	providers := []Provider{
+		{Name: "null", Source: "hashicorp/null", Version: "3.2.1", Constraints: "~> 3.2"},
+	}
+
+	for _, test := range cases {
+		out, err := UpdateLock([]byte(test.in), providers, &registry)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
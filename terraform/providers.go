@@ -0,0 +1,154 @@
+package terraform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoProviders = errors.New("no `required_providers` block")
+)
+
+// Provider A provider from a `required_providers` block.
+type Provider struct {
+	Name        string
+	Source      string
+	Version     string
+	Constraints string
+}
+
+var (
+	requiredProvidersRe = regexp.MustCompile(`^\s*required_providers\s*{`)
+	providerBlockRe     = regexp.MustCompile(`^\s*([\w-]+)\s*=\s*{`)
+	providerShortRe     = regexp.MustCompile(`^(\s*([\w-]+)\s*=\s*")([^"]*)(".*)$`)
+	attributeRe         = regexp.MustCompile(`^(\s*(source|version)\s*=\s*")([^"]*)(".*)$`)
+)
+
+// Update Bump the provider versions of all `required_providers` blocks to
+// the latest version available in the registry. The updated providers are
+// returned so the lock file can be updated with UpdateLock.
+func Update(bytesIn []byte, registry *Registry) (string, []Provider, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+
+	var updated []Provider
+	var current *Provider
+	var versionLine int
+
+	found := false
+	depth := 0
+
+	for i, line := range lines {
+		if depth == 0 {
+			if requiredProvidersRe.MatchString(line) {
+				found = true
+				depth = 1
+			}
+
+			continue
+		}
+
+		if depth == 1 {
+			if match := providerBlockRe.FindStringSubmatch(line); len(match) > 0 {
+				current = &Provider{Name: match[1]}
+				versionLine = -1
+			} else if match := providerShortRe.FindStringSubmatch(line); len(match) > 0 {
+				// legacy syntax, `aws = "~> 3.0"`
+				provider := Provider{
+					Name:        match[2],
+					Source:      "hashicorp/" + match[2],
+					Constraints: match[3],
+				}
+
+				if bumpProvider(&provider, registry) {
+					lines[i] = match[1] + provider.Constraints + match[4]
+					updated = append(updated, provider)
+				}
+			}
+		}
+
+		if current != nil {
+			if match := attributeRe.FindStringSubmatch(line); len(match) > 0 {
+				switch match[2] {
+				case "source":
+					current.Source = match[3]
+				case "version":
+					current.Constraints = match[3]
+					versionLine = i
+				}
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+
+		if current != nil && depth == 1 {
+			if current.Source == "" {
+				current.Source = "hashicorp/" + current.Name
+			}
+
+			if versionLine >= 0 && bumpProvider(current, registry) {
+				match := attributeRe.FindStringSubmatch(lines[versionLine])
+				lines[versionLine] = match[1] + current.Constraints + match[4]
+				updated = append(updated, *current)
+			}
+
+			current = nil
+		}
+	}
+
+	if !found {
+		return "", nil, ErrorNoProviders
+	}
+
+	return strings.Join(lines, "\n"), updated, nil
+}
+
+// bumpProvider Set the provider version to the latest available version and
+// update its constraint. Returns false if the provider was left untouched.
+func bumpProvider(provider *Provider, registry *Registry) bool {
+	constraint, err := versions.ParseConstraint(provider.Constraints)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"provider": provider,
+		}).Debug("Cannot handle the provider constraint")
+
+		return false
+	}
+
+	if constraint.Fixed() {
+		log.WithFields(log.Fields{
+			"provider": provider,
+		}).Debug("Provider constraint is a bound, leaving it as is")
+
+		return false
+	}
+
+	available, err := registry.Versions(provider.Source)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":      err,
+			"provider": provider,
+		}).Error("Cannot retrieve provider versions")
+
+		return false
+	}
+
+	latest, err := versions.Latest(available)
+	if err != nil {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"provider": provider.Source,
+		"latest":   latest,
+	}).Debug("Latest provider version")
+
+	provider.Version = latest
+	provider.Constraints = constraint.Bump(latest)
+
+	return true
+}
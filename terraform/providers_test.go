@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	var cases = []struct {
+		name      string
+		in        string
+		out       string
+		providers []Provider
+		err       error
+	}{
+		{
+			name: "required_providers with source and version",
+			in: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      source  = "hashicorp/null"
+				      version = "~> 3.1"
+				    }
+				  }
+				}
+			`),
+			out: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      source  = "hashicorp/null"
+				      version = "~> 3.2"
+				    }
+				  }
+				}
+			`),
+			providers: []Provider{
+				{Name: "null", Source: "hashicorp/null", Version: "3.2.1", Constraints: "~> 3.2"},
+			},
+		},
+		{
+			name: "legacy provider syntax and exact version",
+			in: heredoc.Doc(`
+				terraform {
+				  required_version = ">= 1.0"
+				  required_providers {
+				    null = "3.1.0"
+				  }
+				}
+			`),
+			out: heredoc.Doc(`
+				terraform {
+				  required_version = ">= 1.0"
+				  required_providers {
+				    null = "3.2.1"
+				  }
+				}
+			`),
+			providers: []Provider{
+				{Name: "null", Source: "hashicorp/null", Version: "3.2.1", Constraints: "3.2.1"},
+			},
+		},
+		{
+			name: "range constraints are left untouched",
+			in: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      version = ">= 2.0, < 3.0"
+				    }
+				  }
+				}
+			`),
+			out: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      version = ">= 2.0, < 3.0"
+				    }
+				  }
+				}
+			`),
+		},
+		{
+			name: "upper bound is left untouched",
+			in: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      source  = "hashicorp/null"
+				      version = "< 3.0"
+				    }
+				  }
+				}
+			`),
+			out: heredoc.Doc(`
+				terraform {
+				  required_providers {
+				    null = {
+				      source  = "hashicorp/null"
+				      version = "< 3.0"
+				    }
+				  }
+				}
+			`),
+		},
+		{
+			name: "file without required_providers",
+			in:   `resource "null_resource" "this" {}`,
+			err:  ErrorNoProviders,
+		},
+	}
+
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL}
+
+	for _, test := range cases {
+		out, providers, err := Update([]byte(test.in), &registry)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+		assert.Equal(t, test.providers, providers, test.name)
+	}
+}
@@ -0,0 +1,375 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"github.com/pkg/errors"
+)
+
+const DefaultHost = "registry.terraform.io"
+
+var (
+	ErrorWrongSource   = errors.New("cannot parse provider source")
+	ErrorNoProvidersV1 = errors.New("registry does not support the provider protocol")
+)
+
+// Registry A client for the terraform provider registry protocol.
+type Registry struct {
+	// URL overrides the host of every provider source, for example to use a
+	// local registry.
+	URL    string
+	Client *http.Client
+	// Platforms selects the `os_arch` platforms of the `h1:` hashes, for
+	// example `linux_amd64`. It defaults to the running platform, same as
+	// `terraform init`, as the `zh:` hashes of every platform are locked
+	// anyway.
+	Platforms []string
+}
+
+type platform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+type providerVersion struct {
+	Version   string     `json:"version"`
+	Platforms []platform `json:"platforms"`
+}
+
+type downloadInfo struct {
+	Filename    string `json:"filename"`
+	DownloadURL string `json:"download_url"`
+	ShasumsURL  string `json:"shasums_url"`
+	Shasum      string `json:"shasum"`
+}
+
+// Address Return the fully qualified address of a provider source, for
+// example `hashicorp/aws` becomes `registry.terraform.io/hashicorp/aws`.
+func Address(source string) (string, error) {
+	parts := strings.Split(strings.ToLower(source), "/")
+
+	switch len(parts) {
+	case 2:
+		return strings.Join(append([]string{DefaultHost}, parts...), "/"), nil
+	case 3:
+		return strings.Join(parts, "/"), nil
+	}
+
+	return "", ErrorWrongSource
+}
+
+func (r *Registry) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+
+	return r.Client
+}
+
+func (r *Registry) get(address string, v interface{}) error {
+	resp, err := r.client().Get(address)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get %s", address)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s: %s", address, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", address)
+	}
+
+	if data, ok := v.(*[]byte); ok {
+		*data = body
+
+		return nil
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// providersURL Find the base URL of the provider API with the service
+// discovery protocol.
+func (r *Registry) providersURL(source string) (string, error) {
+	address, err := Address(source)
+	if err != nil {
+		return "", err
+	}
+
+	host := "https://" + strings.SplitN(address, "/", 2)[0]
+	if r.URL != "" {
+		host = strings.TrimSuffix(r.URL, "/")
+	}
+
+	var services map[string]string
+
+	err = r.get(host+"/.well-known/terraform.json", &services)
+	if err != nil {
+		return "", err
+	}
+
+	providers, ok := services["providers.v1"]
+	if !ok {
+		return "", ErrorNoProvidersV1
+	}
+
+	base, err := url.Parse(host + "/")
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(providers)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(base.ResolveReference(ref).String(), "/") + "/" +
+		strings.Join(strings.Split(address, "/")[1:], "/"), nil
+}
+
+// versions Get the available versions of a provider, with their
+// platforms.
+func (r *Registry) versions(base string) ([]providerVersion, error) {
+	var resp struct {
+		Versions []providerVersion `json:"versions"`
+	}
+
+	err := r.get(base+"/versions", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Versions, nil
+}
+
+// Versions List the available versions of a provider.
+func (r *Registry) Versions(source string) ([]string, error) {
+	base, err := r.providersURL(source)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := r.versions(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, version := range available {
+		ret = append(ret, version.Version)
+	}
+
+	return ret, nil
+}
+
+// platforms Return the platforms of a provider version that the registry
+// selects.
+func (r *Registry) platforms(base, version string) ([]platform, error) {
+	available, err := r.versions(base)
+	if err != nil {
+		return nil, err
+	}
+
+	names := r.Platforms
+	if len(names) == 0 {
+		names = []string{runtime.GOOS + "_" + runtime.GOARCH}
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var ret []platform
+
+	for _, v := range available {
+		if v.Version != version {
+			continue
+		}
+
+		for _, p := range v.Platforms {
+			if wanted[p.OS+"_"+p.Arch] {
+				ret = append(ret, p)
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no platforms found for version %s", version)
+	}
+
+	return ret, nil
+}
+
+// zhHashes Return the `zh:` hashes of the archives of a SHA256SUMS file.
+func zhHashes(shasums []byte) []string {
+	var ret []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(shasums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !strings.HasSuffix(fields[1], ".zip") {
+			continue
+		}
+
+		ret = append(ret, "zh:"+fields[0])
+	}
+
+	return ret
+}
+
+// h1Hash Download the archive of a platform to a temporary file, checking
+// its sha256 on the way, and calculate its `h1:` hash.
+func (r *Registry) h1Hash(info downloadInfo) (string, error) {
+	resp, err := r.client().Get(info.DownloadURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get %s", info.DownloadURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get %s: %s", info.DownloadURL, resp.Status)
+	}
+
+	archive, err := ioutil.TempFile("", "terraform-provider-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	shasum := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(archive, shasum), resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot download %s", info.DownloadURL)
+	}
+
+	if sum := fmt.Sprintf("%x", shasum.Sum(nil)); info.Shasum != "" && sum != info.Shasum {
+		return "", fmt.Errorf("checksum mismatch for %s: %s != %s", info.Filename, sum, info.Shasum)
+	}
+
+	return hashZip(archive, size)
+}
+
+// Hashes Calculate the hashes of a provider version as they are written in
+// `.terraform.lock.hcl`. The `zh:` hashes are taken from the published
+// SHA256SUMS file and the `h1:` hashes are calculated for every platform of
+// the platforms of the registry.
+func (r *Registry) Hashes(source, version string) ([]string, error) {
+	base, err := r.providersURL(source)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms, err := r.platforms(base, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find the platforms of %s", source)
+	}
+
+	var ret []string
+
+	for i, p := range platforms {
+		var info downloadInfo
+
+		err = r.get(fmt.Sprintf("%s/%s/download/%s/%s", base, version, p.OS, p.Arch), &info)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			var shasums []byte
+
+			err = r.get(info.ShasumsURL, &shasums)
+			if err != nil {
+				return nil, err
+			}
+
+			ret = append(ret, zhHashes(shasums)...)
+		}
+
+		h1, err := r.h1Hash(info)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, h1)
+	}
+
+	sort.Strings(ret)
+
+	log.WithFields(log.Fields{
+		"source":    source,
+		"version":   version,
+		"platforms": len(platforms),
+		"hashes":    len(ret),
+	}).Debug("Provider hashes")
+
+	return ret, nil
+}
+
+// hashZip Calculate the `h1:` hash of a zip archive, which is the
+// golang.org/x/mod/sumdb/dirhash Hash1 of the archive contents.
+func hashZip(archive io.ReaderAt, size int64) (string, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot open provider archive")
+	}
+
+	var files []string
+	contents := map[string]*zip.File{}
+
+	for _, file := range reader.File {
+		if strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+
+		files = append(files, file.Name)
+		contents[file.Name] = file
+	}
+
+	sort.Strings(files)
+
+	summary := sha256.New()
+
+	for _, name := range files {
+		if strings.Contains(name, "\n") {
+			return "", fmt.Errorf("file name with a newline: %q", name)
+		}
+
+		file, err := contents[name].Open()
+		if err != nil {
+			return "", err
+		}
+
+		sum := sha256.New()
+		_, err = io.Copy(sum, file)
+		file.Close()
+
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(summary, "%x  %s\n", sum.Sum(nil), name)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
@@ -0,0 +1,162 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func providerZip(t *testing.T, contents string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+
+	file, err := writer.Create("terraform-provider-null")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.Write([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// testRegistry A local stand-in of registry.terraform.io serving the
+// `hashicorp/null` provider for linux/amd64 and darwin/arm64.
+func testRegistry(t *testing.T) *httptest.Server {
+	archives := map[string][]byte{
+		"linux_amd64":  providerZip(t, "null"),
+		"darwin_arm64": providerZip(t, "null darwin"),
+	}
+	shasums := fmt.Sprintf(
+		"%x  terraform-provider-null_3.2.1_linux_amd64.zip\n"+
+			"%x  terraform-provider-null_3.2.1_darwin_arm64.zip\n"+
+			"1111111111111111111111111111111111111111111111111111111111111111  terraform-provider-null_3.2.1_manifest.json\n",
+		sha256.Sum256(archives["linux_amd64"]), sha256.Sum256(archives["darwin_arm64"]),
+	)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"providers.v1": "/v1/providers/"}`)
+	})
+	mux.HandleFunc("/v1/providers/hashicorp/null/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": [
+			{"version": "3.1.0"},
+			{"version": "3.2.1", "platforms": [{"os": "linux", "arch": "amd64"}, {"os": "darwin", "arch": "arm64"}]},
+			{"version": "3.3.0-alpha1"},
+			{"version": "2.1.2"}
+		]}`)
+	})
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, shasums)
+	})
+
+	for name, archive := range archives {
+		name, archive := name, archive
+		parts := strings.Split(name, "_")
+
+		mux.HandleFunc(fmt.Sprintf("/v1/providers/hashicorp/null/3.2.1/download/%s/%s", parts[0], parts[1]), func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"filename": "terraform-provider-null_3.2.1_%[3]s.zip",
+				"download_url": "%[1]s/%[3]s.zip",
+				"shasums_url": "%[1]s/SHA256SUMS",
+				"shasum": "%[2]x"
+			}`, server.URL, sha256.Sum256(archive), name)
+		})
+		mux.HandleFunc("/"+name+".zip", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive)
+		})
+	}
+
+	return server
+}
+
+func TestAddress(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "short source",
+			in:   "hashicorp/aws",
+			out:  "registry.terraform.io/hashicorp/aws",
+		},
+		{
+			name: "fully qualified source",
+			in:   "example.com/Foo/bar",
+			out:  "example.com/foo/bar",
+		},
+		{
+			name: "invalid source",
+			in:   "aws",
+			err:  ErrorWrongSource,
+		},
+	}
+
+	for _, test := range cases {
+		address, err := Address(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, address, test.name)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	server := testRegistry(t)
+	defer server.Close()
+
+	registry := Registry{URL: server.URL, Platforms: []string{"linux_amd64", "darwin_arm64"}}
+
+	available, err := registry.Versions("hashicorp/null")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3.1.0", "3.2.1", "3.3.0-alpha1", "2.1.2"}, available)
+
+	hashes, err := registry.Hashes("hashicorp/null", "3.2.1")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(hashes))
+	assert.True(t, strings.HasPrefix(hashes[0], "h1:"))
+	assert.True(t, strings.HasPrefix(hashes[1], "h1:"))
+	assert.NotEqual(t, hashes[0], hashes[1])
+
+	registry.Platforms = []string{"linux_amd64"}
+
+	hashes, err = registry.Hashes("hashicorp/null", "3.2.1")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(hashes))
+
+	registry.Platforms = []string{"windows_amd64"}
+
+	_, err = registry.Hashes("hashicorp/null", "3.2.1")
+	assert.NotNil(t, err)
+
+	_, err = registry.Versions("hashicorp/missing")
+	assert.NotNil(t, err)
+}
+
+func TestHashZip(t *testing.T) {
+	archive := providerZip(t, "null")
+
+	hash, err := hashZip(bytes.NewReader(archive), int64(len(archive)))
+	assert.Nil(t, err)
+	assert.Equal(t, "h1:Qkg7skmGGGnA2Ncnmv0LtD5wnvObdwpe2kFckYx523Y=", hash)
+
+	_, err = hashZip(strings.NewReader("not a zip"), 9)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,14 @@
+package tomlvalue
+
+import "regexp"
+
+// ReplaceSubmatch Replace the nth submatch of the regular expression with
+// the updated value.
+func ReplaceSubmatch(re *regexp.Regexp, value string, n int, update func(string) string) string {
+	found := re.FindStringSubmatchIndex(value)
+	if found == nil {
+		return value
+	}
+
+	return value[0:found[2*n]] + update(value[found[2*n]:found[2*n+1]]) + value[found[2*n+1]:]
+}
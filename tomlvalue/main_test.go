@@ -0,0 +1,35 @@
+package tomlvalue
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceSubmatch(t *testing.T) {
+	re := regexp.MustCompile(`version\s*=\s*"([^"]*)"`)
+
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "inline table",
+			in:   `{ version = "1.2.3", features = ["a"] }`,
+			out:  `{ version = "1.2.3-NEW", features = ["a"] }`,
+		},
+		{
+			name: "no match",
+			in:   `"1.2.3"`,
+			out:  `"1.2.3"`,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, ReplaceSubmatch(re, test.in, 1, func(value string) string {
+			return value + "-NEW"
+		}), test.name)
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/tomlvalue"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+const ToolVersionsFile = ".tool-versions"
+
+var (
+	ErrorNoTools = errors.New("no tools found")
+)
+
+var (
+	toolVersionRe = regexp.MustCompile(`^(\s*)([^\s#]+)(\s+)([^\s#]+)`)
+	tableRe       = regexp.MustCompile(`^\s*\[\[?([^\[\]]+)\]\]?\s*(#.*)?$`)
+	keyRe         = regexp.MustCompile(`^(\s*("[^"]+"|'[^']+'|[A-Za-z0-9._:/-]+)\s*=\s*)(.*)$`)
+	quotedRe      = regexp.MustCompile(`["']([^"']*)["']`)
+	versionRe     = regexp.MustCompile(`\bversion\s*=\s*["']([^"']*)["']`)
+)
+
+// IsMise Check if a file name looks like a mise config file, for example
+// `.mise.toml` or `.config/mise/config.toml`.
+func IsMise(file string) bool {
+	switch filepath.Base(file) {
+	case "mise.toml", ".mise.toml", "mise.local.toml", ".mise.local.toml", ".rtx.toml":
+		return true
+	}
+
+	return filepath.Base(file) == "config.toml" && filepath.Base(filepath.Dir(file)) == "mise"
+}
+
+// nextVersion Return the latest tag of a tool that has the prefix of its
+// upstream, without the prefix, keeping the number of version components,
+// so `20` stays a major version.
+func nextVersion(tool, version string, upstreams map[string]Upstream, lister gh.TagLister) string {
+	up, ok := upstream(tool, upstreams)
+	if !ok {
+		log.WithFields(log.Fields{
+			"tool": tool,
+		}).Debug("Unknown tool upstream")
+
+		return version
+	}
+
+	constraint, err := versions.ParseConstraint(version)
+	if err != nil || constraint.Operator != "" {
+		log.WithFields(log.Fields{
+			"tool":    tool,
+			"version": version,
+		}).Debug("Version is not updated")
+
+		return version
+	}
+
+	parts := strings.SplitN(up.Repo, "/", 2)
+
+	tags, err := lister.Tags(parts[0], parts[1])
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":  err,
+			"tool": tool,
+			"repo": up.Repo,
+		}).Error("Cannot list the tags")
+
+		return version
+	}
+
+	next, err := versions.LatestPrefixed(tags, up.Prefix)
+	if err != nil || !versions.Less(version, next) {
+		log.WithFields(log.Fields{
+			"err":     err,
+			"tool":    tool,
+			"version": version,
+		}).Debug("No newer tag")
+
+		return version
+	}
+
+	return constraint.Bump(next)
+}
+
+// UpdateToolVersions Update the first version of every tool of an asdf
+// `.tool-versions` file, keeping the whitespace layout of the file.
+// Fallback versions, `system` and `ref:` versions are left as they are.
+func UpdateToolVersions(bytesIn []byte, upstreams map[string]Upstream, lister gh.TagLister) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	found := false
+
+	for i, line := range lines {
+		match := toolVersionRe.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+
+		found = true
+		tool := line[match[4]:match[5]]
+		version := line[match[8]:match[9]]
+
+		lines[i] = line[0:match[8]] + nextVersion(tool, version, upstreams, lister) + line[match[9]:]
+	}
+
+	if !found {
+		return "", ErrorNoTools
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// UpdateMise Update the `[tools]` of a mise config file. The value of a
+// tool can be a version, a list of versions, of which the first one is
+// updated, or a table with a `version`.
+func UpdateMise(bytesIn []byte, upstreams map[string]Upstream, lister gh.TagLister) (string, error) {
+	lines := strings.Split(string(bytesIn), "\n")
+	table := ""
+	found := false
+
+	for i, line := range lines {
+		if match := tableRe.FindStringSubmatch(line); match != nil {
+			table = strings.TrimSpace(match[1])
+
+			continue
+		}
+
+		match := keyRe.FindStringSubmatch(line)
+		if match == nil || table != "tools" {
+			continue
+		}
+
+		found = true
+		tool := strings.Trim(match[2], `"'`)
+
+		re := quotedRe
+		if strings.HasPrefix(strings.TrimSpace(match[3]), "{") {
+			re = versionRe
+		}
+
+		lines[i] = match[1] + tomlvalue.ReplaceSubmatch(re, match[3], 0, func(quoted string) string {
+			return tomlvalue.ReplaceSubmatch(quotedRe, quoted, 1, func(version string) string {
+				return nextVersion(tool, version, upstreams, lister)
+			})
+		})
+	}
+
+	if !found {
+		return "", ErrorNoTools
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
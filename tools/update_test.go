@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTags A stand-in of the GitHub tags, with the tags the repositories
+// really have, like the `weekly.*` and `go1.22rc1` tags of golang/go and the
+// tags of the other modules of kubernetes-sigs/kustomize.
+type testTags map[string][]string
+
+func (tags testTags) Tags(owner, repo string) ([]string, error) {
+	ret, ok := tags[owner+"/"+repo]
+	if !ok {
+		return nil, fmt.Errorf("unknown repository %s/%s", owner, repo)
+	}
+
+	return ret, nil
+}
+
+var testLister = testTags{
+	"hashicorp/terraform": {"v1.8.0-beta1", "v1.7.5", "v1.7.4", "v1.5.7"},
+	"golang/go":           {"weekly.2012-03-27", "release.r60", "go1.22rc1", "go1.22.1", "go1.22.0", "go1.21.3", "go1.9"},
+	"nodejs/node":         {"v21.7.1", "v20.11.1", "heads/v0.10.0"},
+	"jqlang/jq":           {"jq-1.7.1", "jq-1.7rc2", "jq-1.6", "jq-1.5"},
+	"kubernetes-sigs/kustomize": {
+		"kyaml/v0.17.0", "api/v0.16.0", "kustomize/v5.3.0", "kustomize/v5.2.1", "cmd/config/v0.13.0",
+	},
+	"owner/mytool": {"v0.3.0", "v0.2.0", "latest"},
+	"cli/cli":      {"v2.46.0", "v2.45.0"},
+}
+
+func TestUpdateToolVersions(t *testing.T) {
+	in := heredoc.Doc(`
+		# tools
+		terraform   1.5.7
+		golang	1.21.3 1.20.0 # fallback
+		nodejs      20
+		python      system
+		unknown     1.0.0
+		mytool 0.1.0
+		kustomize 5.2.1
+	`)
+
+	upstreams := map[string]Upstream{
+		"terraform": Upstreams["terraform"],
+		"golang":    Upstreams["golang"],
+		"nodejs":    Upstreams["nodejs"],
+		"mytool":    {Repo: "owner/mytool", Prefix: "v"},
+		"kustomize": Upstreams["kustomize"],
+	}
+
+	out, err := UpdateToolVersions([]byte(in), upstreams, testLister)
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		# tools
+		terraform   1.7.5
+		golang	1.22.1 1.20.0 # fallback
+		nodejs      21
+		python      system
+		unknown     1.0.0
+		mytool 0.3.0
+		kustomize 5.3.0
+	`), out)
+}
+
+func TestUpdateMise(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "tools table",
+			in: heredoc.Doc(`
+				[env]
+				terraform = "1.5.7"
+
+				[tools]
+				terraform = "1.5.7"
+				go = ["1.21.3", "1.20"]
+				jq = { version = '1.6' }
+				"ubi:cli/cli" = "2.40.1" # gh
+				node = "lts"
+			`),
+			out: heredoc.Doc(`
+				[env]
+				terraform = "1.5.7"
+
+				[tools]
+				terraform = "1.7.5"
+				go = ["1.22.1", "1.20"]
+				jq = { version = '1.7' }
+				"ubi:cli/cli" = "2.46.0" # gh
+				node = "lts"
+			`),
+		},
+		{
+			name: "no tools",
+			in:   "[env]\nFOO = \"bar\"\n",
+			err:  ErrorNoTools,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := UpdateMise([]byte(test.in), Upstreams, testLister)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestLoadUpstreams(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "zoi.yaml")
+
+	err := ioutil.WriteFile(config, []byte("tools:\n  mytool:\n    repo: owner/mytool\n    prefix: v\n"), 0644)
+	assert.Nil(t, err)
+
+	upstreams, err := LoadUpstreams(config)
+	assert.Nil(t, err)
+	assert.Equal(t, Upstream{Repo: "owner/mytool", Prefix: "v"}, upstreams["mytool"])
+	assert.Equal(t, Upstreams["terraform"], upstreams["terraform"])
+}
+
+func TestIsMise(t *testing.T) {
+	assert.True(t, IsMise("repo/.mise.toml"))
+	assert.True(t, IsMise("/home/user/.config/mise/config.toml"))
+	assert.False(t, IsMise("Cargo.toml"))
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Upstream The GitHub repository that releases a tool, and the prefix of
+// its tags, for example `go` for the `go1.21.3` tags of `golang/go`.
+type Upstream struct {
+	Repo   string `yaml:"repo"`
+	Prefix string `yaml:"prefix"`
+}
+
+// Upstreams The upstreams of the common asdf and mise tools.
+var Upstreams = map[string]Upstream{
+	"act":            {Repo: "nektos/act", Prefix: "v"},
+	"awscli":         {Repo: "aws/aws-cli"},
+	"direnv":         {Repo: "direnv/direnv", Prefix: "v"},
+	"gh":             {Repo: "cli/cli", Prefix: "v"},
+	"github-cli":     {Repo: "cli/cli", Prefix: "v"},
+	"go":             {Repo: "golang/go", Prefix: "go"},
+	"golang":         {Repo: "golang/go", Prefix: "go"},
+	"golangci-lint":  {Repo: "golangci/golangci-lint", Prefix: "v"},
+	"helm":           {Repo: "helm/helm", Prefix: "v"},
+	"jq":             {Repo: "jqlang/jq", Prefix: "jq-"},
+	"k9s":            {Repo: "derailed/k9s", Prefix: "v"},
+	"kind":           {Repo: "kubernetes-sigs/kind", Prefix: "v"},
+	"kubectl":        {Repo: "kubernetes/kubernetes", Prefix: "v"},
+	"kustomize":      {Repo: "kubernetes-sigs/kustomize", Prefix: "kustomize/v"},
+	"node":           {Repo: "nodejs/node", Prefix: "v"},
+	"nodejs":         {Repo: "nodejs/node", Prefix: "v"},
+	"packer":         {Repo: "hashicorp/packer", Prefix: "v"},
+	"pre-commit":     {Repo: "pre-commit/pre-commit", Prefix: "v"},
+	"python":         {Repo: "python/cpython", Prefix: "v"},
+	"shellcheck":     {Repo: "koalaman/shellcheck", Prefix: "v"},
+	"shfmt":          {Repo: "mvdan/sh", Prefix: "v"},
+	"terraform":      {Repo: "hashicorp/terraform", Prefix: "v"},
+	"terraform-docs": {Repo: "terraform-docs/terraform-docs", Prefix: "v"},
+	"terragrunt":     {Repo: "gruntwork-io/terragrunt", Prefix: "v"},
+	"tflint":         {Repo: "terraform-linters/tflint", Prefix: "v"},
+	"vault":          {Repo: "hashicorp/vault", Prefix: "v"},
+	"yq":             {Repo: "mikefarah/yq", Prefix: "v"},
+}
+
+// LoadUpstreams Return the built in upstreams extended with the `tools:`
+// mapping of a config file like
+//
+//	tools:
+//	  mytool:
+//	    repo: owner/mytool
+//	    prefix: v
+func LoadUpstreams(path string) (map[string]Upstream, error) {
+	ret := map[string]Upstream{}
+	for tool, upstream := range Upstreams {
+		ret[tool] = upstream
+	}
+
+	if path == "" {
+		return ret, nil
+	}
+
+	bytesIn, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, err
+	}
+
+	var config struct {
+		Tools map[string]Upstream `yaml:"tools"`
+	}
+
+	err = yaml.Unmarshal(bytesIn, &config)
+	if err != nil {
+		return ret, errors.Wrapf(err, "cannot parse %s", path)
+	}
+
+	for tool, upstream := range config.Tools {
+		if len(strings.Split(upstream.Repo, "/")) != 2 {
+			return ret, errors.Errorf("tool %s: repo should be owner/repo, got %s", tool, upstream.Repo)
+		}
+
+		ret[tool] = upstream
+	}
+
+	return ret, nil
+}
+
+// upstream Find the upstream of a tool. mise tools with a backend, like
+// `ubi:owner/repo` or `github:owner/repo`, are their own upstream.
+func upstream(tool string, upstreams map[string]Upstream) (Upstream, bool) {
+	if found, ok := upstreams[tool]; ok {
+		return found, true
+	}
+
+	if pos := strings.Index(tool, ":"); pos > 0 {
+		backend, name := tool[0:pos], tool[pos+1:]
+
+		switch backend {
+		case "asdf", "core":
+			return upstream(name, upstreams)
+		case "ubi", "github", "aqua":
+			if len(strings.Split(name, "/")) == 2 {
+				return Upstream{Repo: name, Prefix: "v"}, true
+			}
+		}
+	}
+
+	return Upstream{}, false
+}
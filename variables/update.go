@@ -0,0 +1,317 @@
+package variables
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/docker"
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+)
+
+var (
+	makeRe      = regexp.MustCompile(`^\s*(export\s+|override\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(::?=|:::=|\?=|=)[ \t]*([^\s#]+)\s*(#.*)?$`)
+	shellRe     = regexp.MustCompile(`^\s*(export\s+|readonly\s+|local\s+|declare\s+(-\w+\s+)*)?([A-Za-z_][A-Za-z0-9_]*)=("([^"]*)"|'([^']*)'|([^\s;#"']*))`)
+	dockerRe    = regexp.MustCompile(`^\s*(?i:ARG|ENV)\s`)
+	pairRe      = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)=("([^"]*)"|'([^']*)'|([^\s"'\\]*))`)
+	legacyEnvRe = regexp.MustCompile(`^\s*(?i:ENV)\s+([A-Za-z_][A-Za-z0-9_]*)\s+([^\s=]+)\s*$`)
+	defaultRe   = regexp.MustCompile(`^\$\{[A-Za-z_][A-Za-z0-9_]*:?[-=]([^}]*)\}$`)
+	referenceRe = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)|\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+	urlRe       = regexp.MustCompile(`https://github\.com/[^\s"'<>|;]+`)
+)
+
+// isMakefile Check if a file name looks like a Makefile.
+func isMakefile(file string) bool {
+	switch filepath.Base(file) {
+	case "Makefile", "makefile", "GNUmakefile":
+		return true
+	}
+
+	return filepath.Ext(file) == ".mk"
+}
+
+// IsScript Check if a file can define version variables, like a Makefile,
+// a shell script or a Dockerfile.
+func IsScript(file string) bool {
+	if docker.IsDockerfile(file) || isMakefile(file) {
+		return true
+	}
+
+	switch filepath.Ext(file) {
+	case ".sh", ".bash", ".zsh", ".env":
+		return true
+	}
+
+	return false
+}
+
+// definition A variable definition and the position of its value.
+type definition struct {
+	name  string
+	line  int
+	start int
+	end   int
+	value string
+}
+
+// newDefinition Return the definition of a value, using the default of
+// values like `${TOOL_VERSION:-1.2.3}`.
+func newDefinition(name string, line, start, end int, value string) definition {
+	if found := defaultRe.FindStringSubmatchIndex(value); found != nil {
+		return definition{name, line, start + found[2], start + found[3], value[found[2]:found[3]]}
+	}
+
+	return definition{name, line, start, end, value}
+}
+
+// quotedValue Return the position of the value of a `NAME=value` match,
+// without its quotes.
+func quotedValue(found []int, first int) (int, int) {
+	for i := first; i+1 < len(found); i += 2 {
+		if found[i] >= 0 {
+			return found[i], found[i+1]
+		}
+	}
+
+	return -1, -1
+}
+
+// definitions Find the variable definitions of the file, in the syntax of
+// its type.
+func definitions(file string, lines []string) []definition {
+	var ret []definition
+
+	for i, line := range lines {
+		switch {
+		case docker.IsDockerfile(file):
+			if !dockerRe.MatchString(line) {
+				continue
+			}
+
+			if found := legacyEnvRe.FindStringSubmatchIndex(line); found != nil {
+				ret = append(ret, newDefinition(line[found[2]:found[3]], i, found[4], found[5], line[found[4]:found[5]]))
+
+				continue
+			}
+
+			for _, found := range pairRe.FindAllStringSubmatchIndex(line, -1) {
+				start, end := quotedValue(found, 6)
+				ret = append(ret, newDefinition(line[found[2]:found[3]], i, start, end, line[start:end]))
+			}
+		case isMakefile(file):
+			// recipe lines are shell commands
+			if strings.HasPrefix(line, "\t") {
+				continue
+			}
+
+			if found := makeRe.FindStringSubmatchIndex(line); found != nil {
+				ret = append(ret, newDefinition(line[found[4]:found[5]], i, found[8], found[9], line[found[8]:found[9]]))
+			}
+		default:
+			if found := shellRe.FindStringSubmatchIndex(line); found != nil {
+				start, end := quotedValue(found, 10)
+				ret = append(ret, newDefinition(line[found[6]:found[7]], i, start, end, line[start:end]))
+			}
+		}
+	}
+
+	return ret
+}
+
+// lookup Find the definition a reference of a line refers to, which is the
+// last one before the line, or the first one after it as Makefile
+// variables can be defined later.
+func lookup(defs []definition, name string, line int) (definition, bool) {
+	var ret definition
+	found := false
+
+	for _, def := range defs {
+		if def.name != name {
+			continue
+		}
+
+		if def.line <= line || !found {
+			ret = def
+			found = true
+		}
+
+		if def.line > line {
+			break
+		}
+	}
+
+	return ret, found
+}
+
+// nextValue Return the value of a variable for the next tag, keeping the
+// parts of the tag that do not come from the variable, so `1.2.3` of the
+// tag `tool-v1.2.3` becomes `1.3.0` for `tool-v1.3.0`.
+func nextValue(value, tag, next string) string {
+	core := value
+	if !strings.Contains(tag, core) {
+		core = strings.TrimPrefix(value, "v")
+	}
+
+	pos := strings.Index(tag, core)
+	prefix, suffix := tag[0:pos], tag[pos+len(core):]
+
+	if len(next) < len(prefix)+len(suffix) || !strings.HasPrefix(next, prefix) || !strings.HasSuffix(next, suffix) {
+		return value
+	}
+
+	return value[0:len(value)-len(core)] + next[len(prefix):len(next)-len(suffix)]
+}
+
+// expand Replace the references of a URL of a line with the values of
+// their definitions, or their new values if they are updated, returning
+// the definitions that were used.
+func expand(url string, line int, defs []definition, updates map[definition]string) (string, []definition) {
+	var used []definition
+
+	expanded := referenceRe.ReplaceAllStringFunc(url, func(ref string) string {
+		found := referenceRe.FindStringSubmatch(ref)
+		name := found[1] + found[2] + found[3]
+
+		def, ok := lookup(defs, name, line)
+		if !ok || strings.Contains(def.value, "$") {
+			return ref
+		}
+
+		used = append(used, def)
+
+		if value, ok := updates[def]; ok {
+			return value
+		}
+
+		return def.value
+	})
+
+	return expanded, used
+}
+
+// updateURL Find the version variable that a GitHub URL of a line uses for
+// its release and its new value.
+func updateURL(url string, line int, defs []definition, resolve gh.Resolver) (definition, string, bool) {
+	expanded, used := expand(url, line, defs, nil)
+
+	release, err := gh.ParseReleaseUrl(expanded)
+	if err != nil || len(used) == 0 || strings.Contains(release.Owner+release.Repo+release.Release, "$") {
+		return definition{}, "", false
+	}
+
+	tag := release.Release
+
+	// the longest version that the tag is made of
+	var version definition
+	for _, def := range used {
+		if _, err := versions.Parse(def.value); err == nil && strings.Contains(tag, strings.TrimPrefix(def.value, "v")) && len(def.value) > len(version.value) {
+			version = def
+		}
+	}
+
+	if version.value == "" {
+		return definition{}, "", false
+	}
+
+	next, err := resolve(release)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"url": expanded,
+		}).Error("Cannot find the next release")
+
+		return definition{}, "", false
+	}
+
+	updated, err := gh.ParseReleaseUrl(next)
+	if err != nil {
+		return definition{}, "", false
+	}
+
+	value := nextValue(version.value, tag, updated.Release)
+
+	return version, value, value != version.value
+}
+
+// changes Return the expanded old and new URLs of the lines whose
+// definitions are updated, so that their checksums can be updated too.
+func changes(lines []string, defs []definition, updates map[definition]string) []checksum.Change {
+	var ret []checksum.Change
+
+	for i, line := range lines {
+		for _, url := range urlRe.FindAllString(line, -1) {
+			if !strings.Contains(url, "$") {
+				continue
+			}
+
+			oldURL, _ := expand(url, i, defs, nil)
+			newURL, _ := expand(url, i, defs, updates)
+
+			if oldURL != newURL && !strings.Contains(oldURL, "$") {
+				ret = append(ret, checksum.Change{Line: i, OldURL: oldURL, NewURL: newURL})
+			}
+		}
+	}
+
+	return ret
+}
+
+// Update Update the definitions of the version variables that GitHub URLs
+// of Makefiles, shell scripts and Dockerfiles are built from, like the
+// `TOOL_VERSION := 1.2.3` of `.../releases/download/v$(TOOL_VERSION)/...`.
+// It also returns the URLs that changed with the definitions, which
+// checksum.UpdateURLs takes to update their checksums.
+func Update(file string, lines []string, resolve gh.Resolver) ([]string, []checksum.Change) {
+	defs := definitions(file, lines)
+	if len(defs) == 0 {
+		return lines, nil
+	}
+
+	// updates The new values of the definitions, by line and position.
+	updates := map[definition]string{}
+
+	for i, line := range lines {
+		for _, url := range urlRe.FindAllString(line, -1) {
+			if !strings.Contains(url, "$") {
+				continue
+			}
+
+			def, value, ok := updateURL(url, i, defs, resolve)
+			if _, done := updates[def]; !ok || done {
+				continue
+			}
+
+			log.WithFields(log.Fields{
+				"variable": def.name,
+				"value":    value,
+			}).Debug("Updating version variable")
+
+			updates[def] = value
+		}
+	}
+
+	ordered := make([]definition, 0, len(updates))
+	for def := range updates {
+		ordered = append(ordered, def)
+	}
+
+	// definitions that share a line are replaced right to left
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].line != ordered[j].line {
+			return ordered[i].line < ordered[j].line
+		}
+
+		return ordered[i].start > ordered[j].start
+	})
+
+	ret := append([]string{}, lines...)
+	for _, def := range ordered {
+		ret[def.line] = ret[def.line][0:def.start] + updates[def] + ret[def.line][def.end:]
+	}
+
+	return ret, changes(lines, defs, updates)
+}
@@ -0,0 +1,148 @@
+package variables
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/checksum"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"hashicorp/terraform": "v1.6.0",
+	"mikefarah/yq":        "v4.40.5",
+	"owner/tool":          "tool-v2.0.0",
+})
+
+func TestUpdate(t *testing.T) {
+	var cases = []struct {
+		name string
+		file string
+		in   string
+		out  string
+	}{
+		{
+			name: "Makefile",
+			file: "Makefile",
+			in: heredoc.Doc(`
+				TERRAFORM_VERSION := 1.5.7
+				OS ?= linux
+				TOOL_VERSION = 1.0.0 # pinned
+
+				bin/terraform:
+					TERRAFORM_VERSION=0.0.1 echo
+					curl -sLo $@.zip https://github.com/hashicorp/terraform/archive/refs/tags/v$(TERRAFORM_VERSION).zip
+					curl -sL https://github.com/owner/tool/releases/download/tool-v${TOOL_VERSION}/tool_$(OS).tar.gz
+			`),
+			out: heredoc.Doc(`
+				TERRAFORM_VERSION := 1.6.0
+				OS ?= linux
+				TOOL_VERSION = 2.0.0 # pinned
+
+				bin/terraform:
+					TERRAFORM_VERSION=0.0.1 echo
+					curl -sLo $@.zip https://github.com/hashicorp/terraform/archive/refs/tags/v$(TERRAFORM_VERSION).zip
+					curl -sL https://github.com/owner/tool/releases/download/tool-v${TOOL_VERSION}/tool_$(OS).tar.gz
+			`),
+		},
+		{
+			name: "shell script",
+			file: "install.sh",
+			in: heredoc.Doc(`
+				#!/bin/bash
+				export YQ_VERSION="${YQ_VERSION:-v4.35.1}"
+				ARCH=amd64
+				UNKNOWN_VERSION=1.0.0
+				curl -sL "https://github.com/mikefarah/yq/releases/download/${YQ_VERSION}/yq_linux_$ARCH" -o yq
+				curl -sL "https://github.com/owner/unknown/releases/download/v$UNKNOWN_VERSION/unknown"
+			`),
+			out: heredoc.Doc(`
+				#!/bin/bash
+				export YQ_VERSION="${YQ_VERSION:-v4.40.5}"
+				ARCH=amd64
+				UNKNOWN_VERSION=1.0.0
+				curl -sL "https://github.com/mikefarah/yq/releases/download/${YQ_VERSION}/yq_linux_$ARCH" -o yq
+				curl -sL "https://github.com/owner/unknown/releases/download/v$UNKNOWN_VERSION/unknown"
+			`),
+		},
+		{
+			name: "Dockerfile",
+			file: "Dockerfile",
+			in: heredoc.Doc(`
+				FROM alpine:3.19
+				ARG TARGETARCH=amd64 YQ_VERSION=v4.35.1
+				ENV TERRAFORM_VERSION 1.5.7
+				RUN wget https://github.com/mikefarah/yq/releases/download/${YQ_VERSION}/yq_linux_${TARGETARCH} && \
+				    wget https://github.com/hashicorp/terraform/archive/refs/tags/v$TERRAFORM_VERSION.tar.gz
+			`),
+			out: heredoc.Doc(`
+				FROM alpine:3.19
+				ARG TARGETARCH=amd64 YQ_VERSION=v4.40.5
+				ENV TERRAFORM_VERSION 1.6.0
+				RUN wget https://github.com/mikefarah/yq/releases/download/${YQ_VERSION}/yq_linux_${TARGETARCH} && \
+				    wget https://github.com/hashicorp/terraform/archive/refs/tags/v$TERRAFORM_VERSION.tar.gz
+			`),
+		},
+	}
+
+	for _, test := range cases {
+		lines := strings.Split(test.in, "\n")
+		out, _ := Update(test.file, lines, testResolve)
+		assert.Equal(t, test.out, strings.Join(out, "\n"), test.name)
+	}
+}
+
+func TestIsScript(t *testing.T) {
+	assert.True(t, IsScript("build/Makefile"))
+	assert.True(t, IsScript("scripts/install.sh"))
+	assert.True(t, IsScript("Dockerfile.dev"))
+	assert.False(t, IsScript("main.go"))
+}
+
+// TestUpdateAfterRelease Run Update after gh.Release like the line by line
+// path of the command does, which has to leave the variable URLs to Update.
+func TestUpdateAfterRelease(t *testing.T) {
+	in := []string{
+		"TOOL_VERSION := 1.0.0",
+		"TERRAFORM_VERSION := 1.5.7",
+		"\tcurl -sL https://github.com/owner/tool/releases/download/tool-v${TOOL_VERSION}/tool_${TOOL_VERSION}_linux_amd64.tar.gz",
+		"\tcurl -sL https://github.com/hashicorp/terraform/releases/download/v$(TERRAFORM_VERSION)/terraform_linux_amd64.zip",
+	}
+
+	updated := make([]string, len(in))
+	for i, line := range in {
+		updated[i] = gh.Release(line, true, "")
+	}
+
+	out, _ := Update("Makefile", updated, testResolve)
+	assert.Equal(t, []string{
+		"TOOL_VERSION := 2.0.0",
+		"TERRAFORM_VERSION := 1.6.0",
+		in[2],
+		in[3],
+	}, out)
+}
+
+func TestUpdateChanges(t *testing.T) {
+	in := []string{
+		"ARG YQ_VERSION=v4.35.1",
+		"ARG YQ_SHA256=0000000000000000000000000000000000000000000000000000000000000000",
+		"ARG OS=linux",
+		`RUN curl -sLo /usr/local/bin/yq https://github.com/mikefarah/yq/releases/download/${YQ_VERSION}/yq_${OS}_amd64 \`,
+		`    && echo "${YQ_SHA256}  /usr/local/bin/yq" | sha256sum -c`,
+		"RUN curl -sLo /tmp/tool.tar.gz https://github.com/owner/tool/releases/download/tool-v${TOOL_VERSION}/tool.tar.gz",
+	}
+
+	out, changes := Update("Dockerfile", in, testResolve)
+	assert.Equal(t, "ARG YQ_VERSION=v4.40.5", out[0])
+	assert.Equal(t, []checksum.Change{
+		{
+			Line:   3,
+			OldURL: "https://github.com/mikefarah/yq/releases/download/v4.35.1/yq_linux_amd64",
+			NewURL: "https://github.com/mikefarah/yq/releases/download/v4.40.5/yq_linux_amd64",
+		},
+	}, changes)
+}
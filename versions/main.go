@@ -0,0 +1,212 @@
+package versions
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+)
+
+var (
+	ErrorNoVersions        = errors.New("no versions available")
+	ErrorCannotParse       = errors.New("cannot parse version")
+	ErrorComplexConstraint = errors.New("constraint has more than one version")
+	ErrorUnknownPolicy     = errors.New("policy should be one of major, minor or patch")
+)
+
+// Parse Parse a version that might be prefixed with `v` and might have less
+// than 3 components, like `v1.2`.
+func Parse(version string) (*semver.Version, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	core := version
+	extra := ""
+
+	if pos := strings.IndexAny(version, "-+"); pos > 0 {
+		core = version[0:pos]
+		extra = version[pos:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return nil, ErrorCannotParse
+	}
+
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+
+	ret, err := semver.NewVersion(strings.Join(parts, ".") + extra)
+	if err != nil {
+		return nil, ErrorCannotParse
+	}
+
+	return ret, nil
+}
+
+// Latest Find the greatest version that is not a pre-release.
+func Latest(versions []string) (string, error) {
+	var latest string
+	var latestVer *semver.Version
+
+	for _, version := range versions {
+		this, err := Parse(version)
+		if err != nil || this.PreRelease != "" {
+			continue
+		}
+
+		if latestVer == nil || latestVer.LessThan(*this) {
+			latest = version
+			latestVer = this
+		}
+	}
+
+	if latest == "" {
+		return "", ErrorNoVersions
+	}
+
+	return latest, nil
+}
+
+// LatestPrefixed Find the greatest version of the tags that start with the
+// prefix, like the `go1.22.1` of `go`, and return it without the prefix.
+func LatestPrefixed(tags []string, prefix string) (string, error) {
+	var stripped []string
+
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			stripped = append(stripped, strings.TrimPrefix(tag, prefix))
+		}
+	}
+
+	return Latest(stripped)
+}
+
+// Less Compare two versions, with unparsable versions being sorted first.
+func Less(a, b string) bool {
+	aVer, aErr := Parse(a)
+	bVer, bErr := Parse(b)
+
+	if aErr != nil || bErr != nil {
+		return aErr != nil && bErr == nil
+	}
+
+	return aVer.LessThan(*bVer)
+}
+
+var constraintRe = regexp.MustCompile(`^(\s*(~>|>=|<=|==|!=|~=|\^|~|=|>|<)?\s*v?)([0-9][0-9A-Za-z\.\-\+]*)\s*$`)
+
+// Constraint A single version constraint like `~> 1.2` or `^1.2.3`.
+type Constraint struct {
+	Operator string
+	Version  string
+	prefix   string
+}
+
+// ParseConstraint Parse a constraint with a single version in it.
+func ParseConstraint(in string) (*Constraint, error) {
+	if strings.Contains(in, ",") {
+		return nil, ErrorComplexConstraint
+	}
+
+	found := constraintRe.FindStringSubmatch(in)
+	if len(found) == 0 {
+		return nil, ErrorCannotParse
+	}
+
+	return &Constraint{
+		Operator: found[2],
+		Version:  found[3],
+		prefix:   found[1],
+	}, nil
+}
+
+// fixedOperators The operators of exclusions and upper bounds, which
+// would exclude the latest version if they were bumped to it.
+var fixedOperators = map[string]bool{
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+}
+
+// Fixed Check if the constraint is an exclusion or a bound that Bump
+// leaves as it is.
+func (c *Constraint) Fixed() bool {
+	return fixedOperators[c.Operator]
+}
+
+// Bump Update the constraint to the latest version, keeping the operator
+// and the same number of version components, so `~> 1.2` becomes `~> 3.4`
+// and not `~> 3.4.5`. Exclusions and bounds like `!= 1.2`, `< 2.0` or
+// `> 1.0` are returned as they are.
+func (c *Constraint) Bump(latest string) string {
+	if c.Fixed() {
+		return c.prefix + c.Version
+	}
+
+	latest = strings.TrimPrefix(latest, "v")
+	precision := len(strings.Split(c.Version, "."))
+	parts := strings.Split(latest, ".")
+
+	if precision < len(parts) {
+		parts = parts[0:precision]
+	}
+
+	return c.prefix + strings.Join(parts, ".")
+}
+
+// Policy How far an update is allowed to go from the current version.
+type Policy string
+
+const (
+	// PolicyMajor allows any newer version.
+	PolicyMajor Policy = "major"
+	// PolicyMinor allows versions with the same major version.
+	PolicyMinor Policy = "minor"
+	// PolicyPatch allows versions with the same major and minor version.
+	PolicyPatch Policy = "patch"
+)
+
+// ParsePolicy Parse the name of a policy.
+func ParsePolicy(in string) (Policy, error) {
+	switch policy := Policy(in); policy {
+	case PolicyMajor, PolicyMinor, PolicyPatch:
+		return policy, nil
+	}
+
+	return "", ErrorUnknownPolicy
+}
+
+// Filter Return the versions the policy allows as an update of current.
+func (p Policy) Filter(current string, versions []string) []string {
+	currentVer, err := Parse(current)
+	if err != nil {
+		return versions
+	}
+
+	var ret []string
+
+	for _, version := range versions {
+		this, err := Parse(version)
+		if err != nil {
+			continue
+		}
+
+		switch p {
+		case PolicyMinor:
+			if this.Major != currentVer.Major {
+				continue
+			}
+		case PolicyPatch:
+			if this.Major != currentVer.Major || this.Minor != currentVer.Minor {
+				continue
+			}
+		}
+
+		ret = append(ret, version)
+	}
+
+	return ret
+}
@@ -0,0 +1,207 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatest(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   []string
+		out  string
+		err  error
+	}{
+		{
+			name: "unsorted versions",
+			in:   []string{"1.0.0", "1.10.0", "1.2.0"},
+			out:  "1.10.0",
+		},
+		{
+			name: "pre-releases are skipped",
+			in:   []string{"v1.0.0", "v2.0.0-rc1", "v1.1"},
+			out:  "v1.1",
+		},
+		{
+			name: "no valid versions",
+			in:   []string{"latest", "master"},
+			err:  ErrorNoVersions,
+		},
+	}
+
+	for _, test := range cases {
+		latest, err := Latest(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, latest, test.name)
+	}
+}
+
+func TestBump(t *testing.T) {
+	var cases = []struct {
+		name   string
+		in     string
+		latest string
+		out    string
+		err    error
+	}{
+		{
+			name:   "pessimistic constraint",
+			in:     "~> 3.0",
+			latest: "5.31.0",
+			out:    "~> 5.31",
+		},
+		{
+			name:   "exact version",
+			in:     "1.2.3",
+			latest: "1.4.0",
+			out:    "1.4.0",
+		},
+		{
+			name:   "caret constraint with v prefix",
+			in:     "^v1.2",
+			latest: "v2.0.1",
+			out:    "^v2.0",
+		},
+		{
+			name:   "minimum version",
+			in:     ">= 1.0",
+			latest: "1.4.2",
+			out:    ">= 1.4",
+		},
+		{
+			name:   "equal constraint",
+			in:     "= 1.2.3",
+			latest: "1.4.0",
+			out:    "= 1.4.0",
+		},
+		{
+			name:   "excluded version",
+			in:     "!= 1.2.3",
+			latest: "1.4.0",
+			out:    "!= 1.2.3",
+		},
+		{
+			name:   "upper bound",
+			in:     "< 2.0",
+			latest: "3.1.0",
+			out:    "< 2.0",
+		},
+		{
+			name:   "inclusive upper bound",
+			in:     "<= 2.0",
+			latest: "3.1.0",
+			out:    "<= 2.0",
+		},
+		{
+			name:   "exclusive lower bound",
+			in:     "> 1.0",
+			latest: "3.1.0",
+			out:    "> 1.0",
+		},
+		{
+			name: "range constraint",
+			in:   ">= 1.0, < 2.0",
+			err:  ErrorComplexConstraint,
+		},
+		{
+			name: "not a constraint",
+			in:   "latest",
+			err:  ErrorCannotParse,
+		},
+	}
+
+	for _, test := range cases {
+		constraint, err := ParseConstraint(test.in)
+		assert.Equal(t, test.err, err, test.name)
+
+		if err == nil {
+			assert.Equal(t, test.out, constraint.Bump(test.latest), test.name)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  Policy
+		err  error
+	}{
+		{name: "major", in: "major", out: PolicyMajor},
+		{name: "patch", in: "patch", out: PolicyPatch},
+		{name: "unknown policy", in: "latest", err: ErrorUnknownPolicy},
+		{name: "empty policy", in: "", err: ErrorUnknownPolicy},
+	}
+
+	for _, test := range cases {
+		policy, err := ParsePolicy(test.in)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, policy, test.name)
+	}
+}
+
+func TestPolicyFilter(t *testing.T) {
+	available := []string{"1.2.3", "1.2.9", "1.5.0", "2.0.0", "not-a-version"}
+
+	var cases = []struct {
+		name   string
+		policy Policy
+		out    []string
+	}{
+		{
+			name:   "major",
+			policy: PolicyMajor,
+			out:    []string{"1.2.3", "1.2.9", "1.5.0", "2.0.0"},
+		},
+		{
+			name:   "minor",
+			policy: PolicyMinor,
+			out:    []string{"1.2.3", "1.2.9", "1.5.0"},
+		},
+		{
+			name:   "patch",
+			policy: PolicyPatch,
+			out:    []string{"1.2.3", "1.2.9"},
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, test.policy.Filter("1.2", available), test.name)
+	}
+}
+
+func TestLatestPrefixed(t *testing.T) {
+	var cases = []struct {
+		name   string
+		tags   []string
+		prefix string
+		out    string
+		err    error
+	}{
+		{
+			name:   "go tags",
+			tags:   []string{"weekly.2012-03-27", "go1.22rc1", "go1.21.3", "go1.22.1"},
+			prefix: "go",
+			out:    "1.22.1",
+		},
+		{
+			name:   "tags of other modules are skipped",
+			tags:   []string{"kyaml/v0.17.0", "kustomize/v5.3.0", "api/v0.16.0"},
+			prefix: "kustomize/v",
+			out:    "5.3.0",
+		},
+		{
+			name:   "no tags with the prefix",
+			tags:   []string{"v1.0.0"},
+			prefix: "jq-",
+			err:    ErrorNoVersions,
+		},
+	}
+
+	for _, test := range cases {
+		latest, err := LatestPrefixed(test.tags, test.prefix)
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, latest, test.name)
+	}
+}
@@ -0,0 +1,212 @@
+package vim
+
+import (
+	"strings"
+)
+
+// token A lua token. Strings keep the position of their value between the
+// quotes, everything that is not a string, a brace, `=` or a separator is
+// kept as an `other` token.
+type token struct {
+	kind  string
+	text  string
+	start int
+	end   int
+}
+
+const (
+	tokenString = "string"
+	tokenName   = "name"
+	tokenOther  = "other"
+)
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// longBracket Return the closing bracket of a long bracket like `[==[`
+// starting at pos, or an empty string if there is none.
+func longBracket(contents string, pos int) string {
+	if pos >= len(contents) || contents[pos] != '[' {
+		return ""
+	}
+
+	level := 0
+	for pos+1+level < len(contents) && contents[pos+1+level] == '=' {
+		level++
+	}
+
+	if pos+1+level >= len(contents) || contents[pos+1+level] != '[' {
+		return ""
+	}
+
+	return "]" + strings.Repeat("=", level) + "]"
+}
+
+// skipLong Return the position after the end of a long bracket.
+func skipLong(contents string, pos int, closing string) int {
+	end := strings.Index(contents[pos:], closing)
+	if end < 0 {
+		return len(contents)
+	}
+
+	return pos + end + len(closing)
+}
+
+// luaTokens Split lua code into tokens, dropping the comments.
+func luaTokens(contents string) []token {
+	var ret []token
+
+	pos := 0
+	for pos < len(contents) {
+		c := contents[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case strings.HasPrefix(contents[pos:], "--"):
+			if closing := longBracket(contents, pos+2); closing != "" {
+				pos = skipLong(contents, pos+2, closing)
+
+				continue
+			}
+
+			end := strings.IndexByte(contents[pos:], '\n')
+			if end < 0 {
+				end = len(contents) - pos
+			}
+
+			pos += end
+		case c == '"' || c == '\'':
+			end := pos + 1
+			for end < len(contents) && contents[end] != c && contents[end] != '\n' {
+				if contents[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end > len(contents) {
+				end = len(contents)
+			}
+
+			ret = append(ret, token{tokenString, contents[pos+1 : end], pos + 1, end})
+			pos = end + 1
+		case longBracket(contents, pos) != "":
+			pos = skipLong(contents, pos, longBracket(contents, pos))
+			ret = append(ret, token{kind: tokenOther})
+		case c == '{' || c == '}' || c == ',' || c == ';':
+			ret = append(ret, token{string(c), string(c), pos, pos + 1})
+			pos++
+		case strings.IndexByte("=<>~", c) >= 0:
+			end := pos
+			for end < len(contents) && strings.IndexByte("=<>~", contents[end]) >= 0 {
+				end++
+			}
+
+			kind := tokenOther
+			if contents[pos:end] == "=" {
+				kind = "="
+			}
+
+			ret = append(ret, token{kind, contents[pos:end], pos, end})
+			pos = end
+		case isNameByte(c):
+			end := pos
+			for end < len(contents) && isNameByte(contents[end]) {
+				end++
+			}
+
+			ret = append(ret, token{tokenName, contents[pos:end], pos, end})
+			pos = end
+		default:
+			ret = append(ret, token{tokenOther, string(c), pos, pos + 1})
+			pos++
+		}
+	}
+
+	return ret
+}
+
+// isEntryEnd Check if the token ends a table entry.
+func isEntryEnd(tokens []token, i int) bool {
+	return i >= len(tokens) || tokens[i].kind == "," || tokens[i].kind == ";" || tokens[i].kind == "}"
+}
+
+// luaSpecs Find the packer and lazy.nvim plugin specs, which are tables
+// with the plugin name as the first positional string, like
+// `{ "owner/repo", tag = "v1.2" }`. Nested specs like `dependencies` are
+// found too.
+func luaSpecs(contents string) []spec {
+	var ret []spec
+
+	tokens := luaTokens(contents)
+
+	for i, tok := range tokens {
+		if tok.kind != "{" {
+			continue
+		}
+
+		s := spec{fields: map[string]field{}}
+		url := ""
+		depth := 0
+		// entry is set at the start of each entry of the table
+		entry := false
+
+		for j := i; j < len(tokens); j++ {
+			switch tokens[j].kind {
+			case "{":
+				depth++
+				entry = depth == 1
+
+				continue
+			case "}":
+				depth--
+			case ",", ";":
+				entry = depth == 1
+
+				continue
+			}
+
+			if depth == 0 {
+				break
+			}
+
+			if !entry || depth != 1 {
+				continue
+			}
+
+			entry = false
+
+			switch {
+			case tokens[j].kind == tokenString && isEntryEnd(tokens, j+1):
+				if s.name == "" {
+					s.name = tokens[j].text
+				}
+			case tokens[j].kind == tokenName && j+2 < len(tokens) && tokens[j+1].kind == "=" &&
+				tokens[j+2].kind == tokenString && isEntryEnd(tokens, j+3):
+				value := tokens[j+2]
+
+				switch tokens[j].text {
+				case "tag", "commit", "version":
+					s.fields[tokens[j].text] = field{value.start, value.end, value.text}
+				case "url":
+					url = value.text
+				}
+			}
+		}
+
+		if s.name == "" {
+			s.name = url
+		}
+
+		if s.name == "" || len(s.fields) == 0 {
+			continue
+		}
+
+		ret = append(ret, s)
+	}
+
+	return ret
+}
@@ -0,0 +1,40 @@
+package vim
+
+import (
+	"regexp"
+)
+
+var (
+	plugRe   = regexp.MustCompile(`(?m)^[ \t]*Plug[ \t]+['"]([^'"]+)['"][ \t]*,[ \t]*\{([^}\n]*)\}`)
+	optionRe = regexp.MustCompile(`['"](tag|commit)['"]\s*:\s*['"]([^'"]*)['"]`)
+)
+
+// plugSpecs Find the vim-plug `Plug` lines with a `tag` or a `commit`
+// option, like `Plug 'owner/repo', { 'tag': 'v1.2' }`.
+func plugSpecs(contents string) []spec {
+	var ret []spec
+
+	for _, plug := range plugRe.FindAllStringSubmatchIndex(contents, -1) {
+		options := contents[plug[4]:plug[5]]
+		fields := map[string]field{}
+
+		for _, option := range optionRe.FindAllStringSubmatchIndex(options, -1) {
+			fields[options[option[2]:option[3]]] = field{
+				start: plug[4] + option[4],
+				end:   plug[4] + option[5],
+				value: options[option[4]:option[5]],
+			}
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		ret = append(ret, spec{
+			name:   contents[plug[2]:plug[3]],
+			fields: fields,
+		})
+	}
+
+	return ret
+}
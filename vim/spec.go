@@ -0,0 +1,164 @@
+package vim
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/mhristof/zoi/gh"
+	"github.com/mhristof/zoi/log"
+	"github.com/mhristof/zoi/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorNoPlugins = errors.New("no pinned plugins found")
+)
+
+var (
+	shortRe  = regexp.MustCompile(`^([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)$`)
+	githubRe = regexp.MustCompile(`github\.com[/:]([^/]+)/([^/]+?)(\.git)?/?$`)
+)
+
+// IsPlugins Check if the file can have plugin specs, like `.vimrc`,
+// `init.vim` or the lua files of packer and lazy.nvim.
+func IsPlugins(file string) bool {
+	switch filepath.Base(file) {
+	case ".vimrc", "vimrc", "_vimrc", ".gvimrc", "gvimrc":
+		return true
+	}
+
+	ext := filepath.Ext(file)
+
+	return ext == ".vim" || ext == ".lua"
+}
+
+// field A `tag`, `commit` or `version` value of a plugin spec, with the
+// position of the value between its quotes.
+type field struct {
+	start int
+	end   int
+	value string
+}
+
+// spec A plugin with its pinned fields.
+type spec struct {
+	name   string
+	fields map[string]field
+}
+
+// repository Return the GitHub repository of a plugin name, either short
+// like `owner/repo` or a GitHub URL.
+func repository(name string) (string, string, bool) {
+	if found := shortRe.FindStringSubmatch(name); len(found) > 0 {
+		return found[1], found[2], true
+	}
+
+	if found := githubRe.FindStringSubmatch(name); len(found) > 0 {
+		return found[1], found[2], true
+	}
+
+	return "", "", false
+}
+
+// edit A replacement of a part of a file.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// edits Find the new values of the pinned fields of a plugin. A `commit`
+// moves to the commit of the latest tag when the tag is ahead of it, and the
+// `tag` of the same spec moves with it.
+func (s spec) edits(resolve gh.Resolver, repos gh.Repositories) []edit {
+	owner, repo, ok := repository(s.name)
+	if !ok {
+		log.WithFields(log.Fields{
+			"plugin": s.name,
+		}).Debug("Plugin is not hosted on GitHub")
+
+		return nil
+	}
+
+	var ret []edit
+
+	if commit, ok := s.fields["commit"]; ok {
+		tag, sha, moved := nextCommit(owner, repo, commit.value, repos)
+		if moved {
+			ret = append(ret, edit{commit.start, commit.end, sha})
+
+			if current, ok := s.fields["tag"]; ok {
+				ret = append(ret, edit{current.start, current.end, tag})
+			}
+
+			return ret
+		}
+	}
+
+	if tag, ok := s.fields["tag"]; ok {
+		ret = append(ret, edit{tag.start, tag.end, resolve.Next(owner, repo, tag.value)})
+	}
+
+	if version, ok := s.fields["version"]; ok {
+		constraint, err := versions.ParseConstraint(version.value)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"plugin":  s.name,
+				"version": version.value,
+			}).Debug("Cannot parse version, leaving it as is")
+
+			return ret
+		}
+
+		next := resolve.Next(owner, repo, constraint.Version)
+		if versions.Less(constraint.Version, next) {
+			ret = append(ret, edit{version.start, version.end, constraint.Bump(next)})
+		}
+	}
+
+	return ret
+}
+
+// nextCommit Find the commit of the latest tag of a repository if it is
+// ahead of the current commit, shortened to the length of the current one.
+func nextCommit(owner, repo, current string, repos gh.Repositories) (string, string, bool) {
+	tag, sha, moved := gh.NextCommit(repos, owner, repo, current)
+	if moved && len(current) < len(sha) {
+		sha = sha[0:len(current)]
+	}
+
+	return tag, sha, moved
+}
+
+// Update Update the `tag`, `commit` and `version` pins of the plugin specs
+// of vim-plug in vim files, and of packer and lazy.nvim in lua files.
+func Update(file string, bytesIn []byte, resolve gh.Resolver, repos gh.Repositories) (string, error) {
+	var specs []spec
+
+	if filepath.Ext(file) == ".lua" {
+		specs = luaSpecs(string(bytesIn))
+	} else {
+		specs = plugSpecs(string(bytesIn))
+	}
+
+	if len(specs) == 0 {
+		return "", ErrorNoPlugins
+	}
+
+	var edits []edit
+	for _, s := range specs {
+		edits = append(edits, s.edits(resolve, repos)...)
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	ret := string(bytesIn)
+	for _, e := range edits {
+		ret = ret[0:e.start] + e.text + ret[e.end:]
+	}
+
+	return ret, nil
+}
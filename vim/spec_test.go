@@ -0,0 +1,147 @@
+package vim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/zoi/gh"
+	"github.com/stretchr/testify/assert"
+)
+
+// testResolve A stand-in of the GitHub releases.
+var testResolve = gh.StaticResolver(map[string]string{
+	"junegunn/fzf":                    "v0.46.0",
+	"nvim-treesitter/nvim-treesitter": "v0.9.2",
+	"folke/tokyonight.nvim":           "v3.0.1",
+	"nvim-lua/plenary.nvim":           "v0.1.4",
+	"VundleVim/Vundle.vim":            "v0.10.2",
+})
+
+// testRepos A stand-in of the GitHub tags and commits.
+type testRepos struct{}
+
+func (testRepos) LatestTag(owner, repo string) (string, string, error) {
+	if owner+"/"+repo != "tpope/vim-fugitive" {
+		return "", "", fmt.Errorf("unknown repository %s/%s", owner, repo)
+	}
+
+	return "v3.7", "96c1009fcf8ce60161cc938d149dd5a66d570756", nil
+}
+
+func (testRepos) Ahead(owner, repo, base, head string) (bool, error) {
+	return base != "ffffff", nil
+}
+
+func TestUpdatePlug(t *testing.T) {
+	in := heredoc.Doc(`
+		call plug#begin()
+		Plug 'junegunn/fzf', { 'tag': 'v0.40.0', 'do': { -> fzf#install() } }
+		Plug 'tpope/vim-fugitive', { 'commit': '46eaf89' }
+		Plug "https://github.com/VundleVim/Vundle.vim.git", {'tag': 'v0.10.0'}
+		Plug 'tpope/vim-surround'
+		Plug 'nvim-treesitter/nvim-treesitter', { 'branch': 'main' }
+		call plug#end()
+	`)
+
+	out, err := Update("init.vim", []byte(in), testResolve, testRepos{})
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		call plug#begin()
+		Plug 'junegunn/fzf', { 'tag': 'v0.46.0', 'do': { -> fzf#install() } }
+		Plug 'tpope/vim-fugitive', { 'commit': '96c1009' }
+		Plug "https://github.com/VundleVim/Vundle.vim.git", {'tag': 'v0.10.2'}
+		Plug 'tpope/vim-surround'
+		Plug 'nvim-treesitter/nvim-treesitter', { 'branch': 'main' }
+		call plug#end()
+	`), out)
+}
+
+func TestUpdateLua(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		out  string
+		err  error
+	}{
+		{
+			name: "lazy.nvim",
+			in: heredoc.Doc(`
+				return {
+				  -- "folke/tokyonight.nvim", tag = "v1.0.0"
+				  {
+				    "folke/tokyonight.nvim",
+				    version = "^2.1",
+				    config = function()
+				      local tag = "v1.0.0"
+				      require("tokyonight").setup({ style = "night" })
+				    end,
+				  },
+				  {
+				    "nvim-treesitter/nvim-treesitter",
+				    tag = 'v0.9.1',
+				    dependencies = { { "nvim-lua/plenary.nvim", tag = "v0.1.3" } },
+				  },
+				  { url = "https://github.com/tpope/vim-fugitive", commit = "46eaf8918b347906789df296143117774e827616", tag = "v3.6" },
+				  { "tpope/vim-fugitive", commit = "ffffff" },
+				  { "folke/which-key.nvim", version = "*" },
+				}
+			`),
+			out: heredoc.Doc(`
+				return {
+				  -- "folke/tokyonight.nvim", tag = "v1.0.0"
+				  {
+				    "folke/tokyonight.nvim",
+				    version = "^3.0",
+				    config = function()
+				      local tag = "v1.0.0"
+				      require("tokyonight").setup({ style = "night" })
+				    end,
+				  },
+				  {
+				    "nvim-treesitter/nvim-treesitter",
+				    tag = 'v0.9.2',
+				    dependencies = { { "nvim-lua/plenary.nvim", tag = "v0.1.4" } },
+				  },
+				  { url = "https://github.com/tpope/vim-fugitive", commit = "96c1009fcf8ce60161cc938d149dd5a66d570756", tag = "v3.7" },
+				  { "tpope/vim-fugitive", commit = "ffffff" },
+				  { "folke/which-key.nvim", version = "*" },
+				}
+			`),
+		},
+		{
+			name: "packer",
+			in: heredoc.Doc(`
+				return require('packer').startup(function(use)
+				  use 'wbthomason/packer.nvim'
+				  use { 'junegunn/fzf', tag = 'v0.40.0', run = './install --bin' }
+				end)
+			`),
+			out: heredoc.Doc(`
+				return require('packer').startup(function(use)
+				  use 'wbthomason/packer.nvim'
+				  use { 'junegunn/fzf', tag = 'v0.46.0', run = './install --bin' }
+				end)
+			`),
+		},
+		{
+			name: "no plugins",
+			in: heredoc.Doc(`
+				vim.opt.number = true
+			`),
+			err: ErrorNoPlugins,
+		},
+	}
+
+	for _, test := range cases {
+		out, err := Update("plugins.lua", []byte(test.in), testResolve, testRepos{})
+		assert.Equal(t, test.err, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestIsPlugins(t *testing.T) {
+	assert.True(t, IsPlugins("home/.vimrc"))
+	assert.True(t, IsPlugins(".config/nvim/lua/plugins/init.lua"))
+	assert.False(t, IsPlugins("main.go"))
+}
@@ -0,0 +1,74 @@
+package yamlnode
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mhristof/zoi/log"
+	"gopkg.in/yaml.v3"
+)
+
+// MappingValue Return the value of a key of a mapping node.
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// Replace Replace scalars in place, keeping the quotes, the comments and
+// the layout of the file. The scalars of a line are replaced right to left,
+// so that a replacement does not move the columns of the next ones, for
+// example in a flow mapping.
+func Replace(bytesIn []byte, updates map[*yaml.Node]string) string {
+	lines := strings.Split(string(bytesIn), "\n")
+
+	nodes := make([]*yaml.Node, 0, len(updates))
+	for node := range updates {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Line != nodes[j].Line {
+			return nodes[i].Line < nodes[j].Line
+		}
+
+		return nodes[i].Column > nodes[j].Column
+	})
+
+	for _, node := range nodes {
+		updated := updates[node]
+
+		if node.Line < 1 || node.Line > len(lines) {
+			continue
+		}
+
+		line := []rune(lines[node.Line-1])
+		start := node.Column - 1
+
+		if node.Style == yaml.DoubleQuotedStyle || node.Style == yaml.SingleQuotedStyle {
+			start++
+		}
+
+		end := start + len([]rune(node.Value))
+		if start < 0 || end > len(line) || string(line[start:end]) != node.Value {
+			log.WithFields(log.Fields{
+				"value": node.Value,
+				"line":  node.Line,
+			}).Debug("Cannot find the value in the line")
+
+			continue
+		}
+
+		lines[node.Line-1] = string(line[0:start]) + updated + string(line[end:])
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package yamlnode
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestReplace(t *testing.T) {
+	in := heredoc.Doc(`
+		# comment
+		a: 1.0.0 # pinned
+		b: "2.0"
+		c:
+		  d: 'x'
+	`)
+
+	var document yaml.Node
+
+	err := yaml.Unmarshal([]byte(in), &document)
+	assert.Nil(t, err)
+
+	root := document.Content[0]
+	c := MappingValue(root, "c")
+
+	assert.Nil(t, MappingValue(root, "missing"))
+	assert.Equal(t, heredoc.Doc(`
+		# comment
+		a: 1.1.0 # pinned
+		b: "2.1"
+		c:
+		  d: 'y'
+	`), Replace([]byte(in), map[*yaml.Node]string{
+		MappingValue(root, "a"): "1.1.0",
+		MappingValue(root, "b"): "2.1",
+		MappingValue(c, "d"):    "y",
+	}))
+}
+
+func TestReplaceFlowMapping(t *testing.T) {
+	in := "image: {repository: nginx, tag: '1.0', digest: sha256-old}\n"
+
+	var document yaml.Node
+
+	err := yaml.Unmarshal([]byte(in), &document)
+	assert.Nil(t, err)
+
+	image := MappingValue(document.Content[0], "image")
+
+	assert.Equal(t, "image: {repository: nginx, tag: '1.25.3', digest: sha256-new}\n", Replace([]byte(in), map[*yaml.Node]string{
+		MappingValue(image, "tag"):    "1.25.3",
+		MappingValue(image, "digest"): "sha256-new",
+	}))
+}